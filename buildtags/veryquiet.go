@@ -0,0 +1,9 @@
+// Copyright © 2023 Hedzr Yeh.
+
+//go:build veryquiet
+// +build veryquiet
+
+package buildtags
+
+// VeryQuietEnabled identify whether `--tags=veryquiet` has been defined in go building
+const VeryQuietEnabled = true
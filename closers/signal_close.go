@@ -3,6 +3,7 @@ package closers
 import (
 	"fmt"
 	"github.com/hedzr/log/basics"
+	"github.com/hedzr/log/buildtags"
 	"os"
 	"os/signal"
 	"sync"
@@ -27,7 +28,9 @@ func setupCloseHandler(onFinish ...basics.Peripheral) chan struct{} {
 	done := make(chan struct{})
 	go func() {
 		<-c
-		fmt.Println("\r- Ctrl+C pressed in Terminal")
+		if !buildtags.VeryQuietEnabled {
+			fmt.Println("\r- Ctrl+C pressed in Terminal")
+		}
 		for _, f := range onFinish {
 			f.Close()
 		}
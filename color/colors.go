@@ -7,11 +7,15 @@ import (
 	"strings"
 
 	"github.com/hedzr/log"
+	"github.com/hedzr/log/buildtags"
 	"github.com/hedzr/log/states"
 )
 
 // Error outputs formatted message to stderr.
 func Error(format string, args ...interface{}) { //nolint:goprintffuncname //so what
+	if buildtags.VeryQuietEnabled {
+		return
+	}
 	_internalLogTo(func(sb strings.Builder, ln bool) {
 		if ln {
 			_, _ = fmt.Fprint(os.Stderr, sb.String())
@@ -23,11 +27,17 @@ func Error(format string, args ...interface{}) { //nolint:goprintffuncname //so
 }
 
 // Fatal outputs formatted message to stderr.
+// In a veryquiet build, nothing is printed but it still exits (or panics).
 func Fatal(format string, args ...interface{}) { //nolint:goprintffuncname //so what
 	_internalLogTo(func(sb strings.Builder, ln bool) {
-		if ln {
+		switch {
+		case buildtags.VeryQuietEnabled && ln:
+			log.Fatalf("%v", sb.String()) // prints nothing whatever lx is
+		case buildtags.VeryQuietEnabled:
+			log.Panicf("%v", sb.String())
+		case ln:
 			lx.Fatalf("%v", sb.String())
-		} else {
+		default:
 			lx.Panicf("%v", sb.String())
 		}
 	}, format, args...)
@@ -39,7 +49,7 @@ func Fatal(format string, args ...interface{}) { //nolint:goprintffuncname //so
 func Warn(format string, args ...interface{}) { //nolint:goprintffuncname //so what
 	// for the key scene who want quiet output, we may disable
 	// most of the messages by cmdr.SetLogLevel(log.ErrorLevel)
-	if log.GetLevel() < log.WarnLevel {
+	if buildtags.VeryQuietEnabled || !log.GetLevel().Allows(log.WarnLevel) {
 		return
 	}
 
@@ -67,7 +77,7 @@ func Warn(format string, args ...interface{}) { //nolint:goprintffuncname //so w
 func Log(format string, args ...interface{}) { //nolint:goprintffuncname //so what
 	// for the key scene who want quiet output, we may disable
 	// most of the messages by cmdr.SetLogLevel(log.ErrorLevel)
-	if log.GetLevel() < log.WarnLevel {
+	if buildtags.VeryQuietEnabled || !log.GetLevel().Allows(log.WarnLevel) {
 		return
 	}
 
@@ -85,7 +95,7 @@ func Log(format string, args ...interface{}) { //nolint:goprintffuncname //so wh
 // VERBOSE mode.
 // For log.SetLevel(log.ErrorLevel), the text will be discarded.
 func Verbose(format string, args ...interface{}) { //nolint:goprintffuncname //so what
-	if states.Env().IsVerboseMode() {
+	if !buildtags.VeryQuietEnabled && states.Env().IsVerboseMode() {
 		_internalLogTo(func(sb strings.Builder, ln bool) {
 			if ln {
 				print(sb.String()) //nolint:forbidigo //no
@@ -101,7 +111,7 @@ func Verbose(format string, args ...interface{}) { //nolint:goprintffuncname //s
 // is log.TraceLevel, or cmdr is in TRACE mode or trace module
 // is enabled.
 func Trace(format string, args ...interface{}) { //nolint:goprintffuncname //so what
-	if log.GetLevel() == log.TraceLevel || !states.Env().GetTraceMode() {
+	if buildtags.VeryQuietEnabled || log.GetLevel() == log.TraceLevel || !states.Env().GetTraceMode() {
 		return
 	}
 
@@ -121,7 +131,7 @@ func Trace(format string, args ...interface{}) { //nolint:goprintffuncname //so
 func Hilight(format string, args ...interface{}) { //nolint:goprintffuncname //so what
 	// for the key scene who want quiet output, we may disable
 	// most of the messages by cmdr.SetLogLevel(log.ErrorLevel)
-	if log.GetLevel() < log.WarnLevel {
+	if buildtags.VeryQuietEnabled || !log.GetLevel().Allows(log.WarnLevel) {
 		return
 	}
 
@@ -146,7 +156,7 @@ func Hilight(format string, args ...interface{}) { //nolint:goprintffuncname //s
 func DimV(format string, args ...interface{}) { //nolint:goprintffuncname //so what
 	// for the key scene who want quiet output, we may disable
 	// most of the messages by cmdr.SetLogLevel(log.ErrorLevel)
-	if log.GetLevel() < log.WarnLevel {
+	if buildtags.VeryQuietEnabled || !log.GetLevel().Allows(log.WarnLevel) {
 		return
 	}
 
@@ -166,6 +176,9 @@ func DimV(format string, args ...interface{}) { //nolint:goprintffuncname //so w
 
 // Text prints formatted message without any predefined ansi escaping.
 func Text(format string, args ...interface{}) { //nolint:goprintffuncname //so what
+	if buildtags.VeryQuietEnabled {
+		return
+	}
 	_, _ = fmt.Fprintf(os.Stdout, format, args...)
 }
 
@@ -176,7 +189,7 @@ func Text(format string, args ...interface{}) { //nolint:goprintffuncname //so w
 func Dim(format string, args ...interface{}) { //nolint:goprintffuncname //so what
 	// for the key scene who want quiet output, we may disable
 	// most of the messages by cmdr.SetLogLevel(log.ErrorLevel)
-	if log.GetLevel() < log.WarnLevel {
+	if buildtags.VeryQuietEnabled || !log.GetLevel().Allows(log.WarnLevel) {
 		return
 	}
 
@@ -215,7 +228,7 @@ func ToColor(clr Color, format string, args ...interface{}) (str string) {
 func ColoredV(clr Color, format string, args ...interface{}) { //nolint:goprintffuncname //so what
 	// for the key scene who want quiet output, we may disable
 	// most of the messages by cmdr.SetLogLevel(log.ErrorLevel)
-	if log.GetLevel() < log.WarnLevel {
+	if buildtags.VeryQuietEnabled || !log.GetLevel().Allows(log.WarnLevel) {
 		return
 	}
 
@@ -240,7 +253,7 @@ func ColoredV(clr Color, format string, args ...interface{}) { //nolint:goprintf
 func Colored(clr Color, format string, args ...interface{}) { //nolint:goprintffuncname //so what
 	// for the key scene who want quiet output, we may disable
 	// most of the messages by cmdr.SetLogLevel(log.ErrorLevel)
-	if log.GetLevel() < log.WarnLevel {
+	if buildtags.VeryQuietEnabled || !log.GetLevel().Allows(log.WarnLevel) {
 		return
 	}
 
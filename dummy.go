@@ -5,7 +5,6 @@ package log
 import (
 	"fmt"
 	"io"
-	"os"
 )
 
 // NewDummyLogger return a dummy logger
@@ -22,6 +21,7 @@ type dummyLogger struct{}
 
 func (d *dummyLogger) With(key string, val interface{}) Logger         { return d }
 func (d *dummyLogger) WithFields(fields map[string]interface{}) Logger { return d }
+func (d *dummyLogger) WithError(err error) Logger                      { return d }
 
 func (d *dummyLogger) Trace(args ...interface{}) {}
 func (d *dummyLogger) Debug(args ...interface{}) {}
@@ -30,10 +30,7 @@ func (d *dummyLogger) Warn(args ...interface{})  {}
 func (d *dummyLogger) Error(args ...interface{}) {}
 
 func (d *dummyLogger) Fatal(args ...interface{}) {
-	if InTesting() {
-		panic(fmt.Sprint(args...))
-	}
-	os.Exit(1)
+	exitOnFatal(fmt.Sprint(args...))
 }
 
 func (d *dummyLogger) Panic(args ...interface{})              { panic(fmt.Sprint(args...)) }
@@ -46,11 +43,7 @@ func (d *dummyLogger) Warnf(msg string, args ...interface{})  {}
 func (d *dummyLogger) Errorf(msg string, args ...interface{}) {}
 
 func (d *dummyLogger) Fatalf(msg string, args ...interface{}) {
-	// panic("implement me")
-	if InTesting() {
-		panic(fmt.Sprintf(msg, args...))
-	}
-	os.Exit(1)
+	exitOnFatal(fmt.Sprintf(msg, args...))
 }
 
 func (d *dummyLogger) Panicf(msg string, args ...interface{}) { panic(fmt.Sprintf(msg, args...)) }
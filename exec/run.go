@@ -12,6 +12,8 @@ import (
 	"sync"
 
 	"gopkg.in/hedzr/errors.v3"
+
+	"github.com/hedzr/log/buildtags"
 )
 
 // New return a calling object to allow you to make the fluent call.
@@ -212,27 +214,29 @@ func (c *calling) run() (err error) {
 	}
 
 	if !c.quiet {
-		if c.output.Len() > 0 && !ok {
-			if c.leftPadding > 0 {
-				fmt.Print(strings.Repeat(" ", c.leftPadding))
-			}
-			if c.verbose {
-				fmt.Printf("OUTPUT // %v:\n", c.Cmd.Args)
-			} else {
-				fmt.Print("OUTPUT:\n")
-			}
-			_, _ = fmt.Fprintf(os.Stdout, "%v\n", leftPad(c.output.String(), c.leftPadding))
-		}
-		if c.slurp.Len() > 0 && !er && c.retCode != 0 {
-			if c.leftPadding > 0 {
-				_, _ = fmt.Fprintf(os.Stderr, "%v", strings.Repeat(" ", c.leftPadding))
+		if !buildtags.VeryQuietEnabled { // no dumps in a veryquiet build
+			if c.output.Len() > 0 && !ok {
+				if c.leftPadding > 0 {
+					fmt.Print(strings.Repeat(" ", c.leftPadding))
+				}
+				if c.verbose {
+					fmt.Printf("OUTPUT // %v:\n", c.Cmd.Args)
+				} else {
+					fmt.Print("OUTPUT:\n")
+				}
+				_, _ = fmt.Fprintf(os.Stdout, "%v\n", leftPad(c.output.String(), c.leftPadding))
 			}
-			if c.verbose {
-				fmt.Printf("SLURP // %v:\n", c.Cmd.Args)
-			} else {
-				fmt.Print("SLURP:\n")
+			if c.slurp.Len() > 0 && !er && c.retCode != 0 {
+				if c.leftPadding > 0 {
+					_, _ = fmt.Fprintf(os.Stderr, "%v", strings.Repeat(" ", c.leftPadding))
+				}
+				if c.verbose {
+					fmt.Printf("SLURP // %v:\n", c.Cmd.Args)
+				} else {
+					fmt.Print("SLURP:\n")
+				}
+				_, _ = fmt.Fprintf(os.Stderr, "%v\n", leftPad(c.slurp.String(), c.leftPadding))
 			}
-			_, _ = fmt.Fprintf(os.Stderr, "%v\n", leftPad(c.slurp.String(), c.leftPadding))
 		}
 		if err != nil {
 			err = errors.New("system call failed (command-line: %q): %v", c.Args, err)
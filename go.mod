@@ -6,4 +6,7 @@ go 1.11
 
 //replace gopkg.in/hedzr/errors.v3 => ../05.errors
 
-require gopkg.in/hedzr/errors.v3 v3.3.0
+require (
+	gopkg.in/hedzr/errors.v3 v3.3.0
+	gopkg.in/yaml.v2 v2.4.0
+)
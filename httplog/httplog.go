@@ -0,0 +1,254 @@
+/*
+ * Copyright © 2021 Hedzr Yeh.
+ */
+
+// Package httplog provides a net/http middleware which logs the
+// requests through hedzr/log.
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+//	    httplog.FromContext(r.Context()).Infof("hello")
+//	})
+//	_ = http.ListenAndServe(":8080", httplog.New(mux))
+package httplog
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/hedzr/log"
+	"github.com/hedzr/log/timing"
+)
+
+// HeaderRequestID is the header to propagate the request id
+const HeaderRequestID = "X-Request-Id"
+
+// maxRequestIDLen limits the length of an incoming request id
+const maxRequestIDLen = 128
+
+// New returns a middleware which wraps next:
+//
+//   - it takes the request id from X-Request-Id header, or generates a
+//     new one, and writes it back to the response header;
+//   - it attaches a child logger with the method, path and request id
+//     fields to the request context, see FromContext;
+//   - it logs the status, bytes and latency after the request
+//     completed, or marks the entry as aborted if next panicked with
+//     http.ErrAbortHandler;
+//   - it recovers the panic from next into an Error entry with the
+//     stack trace, and responds 500 if nothing has been written.
+//
+// The first call registers httplog by log.RegisterWrapperPackage, so
+// that the callers of the access log are located outside httplog.
+// Importing httplog alone changes nothing.
+func New(next http.Handler, opts ...Opt) http.Handler {
+	registerOnce.Do(func() {
+		log.RegisterWrapperPackage("github.com/hedzr/log/httplog")
+	})
+	m := &middleware{next: next, gen: newRequestID}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Opt is a type for implementing functional options pattern
+type Opt func(m *middleware)
+
+// WithLogger specify the logger for the request loggers, the
+// package-level logger is used by default
+func WithLogger(l log.Logger) Opt {
+	return func(m *middleware) {
+		m.logger = l
+	}
+}
+
+// WithRequestIDGenerator specify a generator for the new request ids
+func WithRequestIDGenerator(gen func() string) Opt {
+	return func(m *middleware) {
+		if gen != nil {
+			m.gen = gen
+		}
+	}
+}
+
+// WithoutAccessLog disables the access log entries
+func WithoutAccessLog() Opt {
+	return func(m *middleware) {
+		m.noAccessLog = true
+	}
+}
+
+// FromContext returns the request logger attached by the middleware,
+// or the package-level logger if there is none.
+func FromContext(ctx context.Context) log.Logger {
+	if v, ok := ctx.Value(ctxKey{}).(*reqInfo); ok {
+		return v.logger
+	}
+	return log.GetLogger()
+}
+
+// RequestID returns the request id attached by the middleware
+func RequestID(ctx context.Context) string {
+	if v, ok := ctx.Value(ctxKey{}).(*reqInfo); ok {
+		return v.id
+	}
+	return ""
+}
+
+// NewContext returns a copy of ctx carrying the request logger l and
+// the request id
+func NewContext(ctx context.Context, l log.Logger, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &reqInfo{logger: l, id: id})
+}
+
+var registerOnce sync.Once
+
+type ctxKey struct{}
+
+type reqInfo struct {
+	logger log.Logger
+	id     string
+}
+
+type middleware struct {
+	next        http.Handler
+	logger      log.Logger
+	gen         func() string
+	noAccessLog bool
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(HeaderRequestID)
+	if !validRequestID(id) {
+		id = m.gen()
+	}
+	w.Header().Set(HeaderRequestID, id)
+
+	l := m.logger
+	if l == nil {
+		l = log.GetLogger()
+	}
+	l = l.WithFields(map[string]interface{}{
+		"method":     r.Method,
+		"path":       r.URL.Path,
+		"request_id": id,
+	})
+
+	rw := &responseWriter{ResponseWriter: w}
+	tp := timing.New().WithoutWriter()
+	aborted := false
+	if !m.noAccessLog {
+		defer func() { accessLog(l, rw, tp, aborted) }()
+	}
+	rePanic := func(v interface{}) bool {
+		aborted = isAbort(v)
+		return aborted
+	}
+	defer log.Recover(log.WithRecoverLogger(l), log.WithRePanicIf(rePanic), log.WithPanicHandler(func(v interface{}) {
+		// the status in the access log is what the client received
+		if !rw.wroteHeader {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	}))
+
+	m.next.ServeHTTP(rw, r.WithContext(NewContext(r.Context(), l, id)))
+}
+
+// isAbort tests if v is http.ErrAbortHandler, which is re-panicked
+// without logging so that net/http aborts the response silently
+func isAbort(v interface{}) bool { return v == http.ErrAbortHandler }
+
+// accessLog writes the access log entry. The status is unknown if the
+// response was aborted, the client might receive a part of it only.
+func accessLog(l log.Logger, rw *responseWriter, tp timing.P, aborted bool) {
+	if aborted {
+		l.WithFields(map[string]interface{}{
+			"aborted": true,
+			"bytes":   rw.bytes,
+			"latency": tp.Duration(),
+		}).Infof("request aborted")
+		return
+	}
+	l.WithFields(map[string]interface{}{
+		"status":  rw.Status(),
+		"bytes":   rw.bytes,
+		"latency": tp.Duration(),
+	}).Infof("request completed")
+}
+
+// validRequestID accepts a non-empty printable ASCII string as an
+// incoming request id
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		log.Warnf("cannot generate request id: %v", err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter records the status and the count of bytes written
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer does
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for the websocket upgrading
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("httplog: the ResponseWriter doesn't support hijacking")
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,185 @@
+/*
+ * Copyright © 2021 Hedzr Yeh.
+ */
+
+package httplog_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hedzr/log"
+	"github.com/hedzr/log/httplog"
+)
+
+type entry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+// recorder records the entries in memory
+type recorder struct {
+	log.Logger
+	fields  map[string]interface{}
+	mu      *sync.Mutex
+	entries *[]entry
+}
+
+func newRecorder() *recorder {
+	return &recorder{Logger: log.NewDummyLogger(), fields: map[string]interface{}{}, mu: &sync.Mutex{}, entries: &[]entry{}}
+}
+
+func (r *recorder) WithFields(fields map[string]interface{}) log.Logger {
+	c := &recorder{Logger: r.Logger, fields: map[string]interface{}{}, mu: r.mu, entries: r.entries}
+	for k, v := range r.fields {
+		c.fields[k] = v
+	}
+	for k, v := range fields {
+		c.fields[k] = v
+	}
+	return c
+}
+
+func (r *recorder) With(key string, val interface{}) log.Logger {
+	return r.WithFields(map[string]interface{}{key: val})
+}
+
+func (r *recorder) add(level, msg string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.entries = append(*r.entries, entry{level, fmt.Sprintf(msg, args...), r.fields})
+}
+
+func (r *recorder) Infof(msg string, args ...interface{})  { r.add("info", msg, args...) }
+func (r *recorder) Errorf(msg string, args ...interface{}) { r.add("error", msg, args...) }
+
+func (r *recorder) all() []entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entry(nil), *r.entries...)
+}
+
+func TestNew(t *testing.T) {
+	rec := newRecorder()
+	var inner log.Logger
+	h := httplog.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner = httplog.FromContext(r.Context())
+		if httplog.RequestID(r.Context()) != "req-1" {
+			t.Errorf("bad request id %q", httplog.RequestID(r.Context()))
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}), httplog.WithLogger(rec))
+
+	req := httptest.NewRequest("POST", "/items?x=1", nil)
+	req.Header.Set(httplog.HeaderRequestID, "req-1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if got := w.Header().Get(httplog.HeaderRequestID); got != "req-1" {
+		t.Errorf("the request id should be propagated, got %q", got)
+	}
+	if r, ok := inner.(*recorder); !ok || r.fields["request_id"] != "req-1" || r.fields["path"] != "/items" || r.fields["method"] != "POST" {
+		t.Errorf("bad request logger: %+v", inner)
+	}
+
+	entries := rec.all()
+	if len(entries) != 1 {
+		t.Fatalf("expect one access log entry, got %+v", entries)
+	}
+	e := entries[0]
+	if e.fields["status"] != http.StatusCreated || e.fields["bytes"] != int64(5) || e.fields["request_id"] != "req-1" {
+		t.Errorf("bad access log entry: %+v", e)
+	}
+	if _, ok := e.fields["latency"].(time.Duration); !ok {
+		t.Errorf("latency should be a time.Duration: %+v", e)
+	}
+}
+
+func TestNew_generateRequestID(t *testing.T) {
+	rec := newRecorder()
+	h := httplog.New(http.NotFoundHandler(), httplog.WithLogger(rec), httplog.WithRequestIDGenerator(func() string { return "gen-1" }))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(httplog.HeaderRequestID, "bad id\n")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if got := w.Header().Get(httplog.HeaderRequestID); got != "gen-1" {
+		t.Errorf("an invalid request id should be replaced, got %q", got)
+	}
+	if e := rec.all()[0]; e.fields["status"] != http.StatusNotFound || e.fields["request_id"] != "gen-1" {
+		t.Errorf("bad access log entry: %+v", e)
+	}
+}
+
+func TestNew_panic(t *testing.T) {
+	rec := newRecorder()
+	h := httplog.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), httplog.WithLogger(rec))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expect 500, got %d", w.Code)
+	}
+	entries := rec.all()
+	if len(entries) != 2 {
+		t.Fatalf("expect an error entry and an access log entry, got %+v", entries)
+	}
+	if e := entries[0]; e.level != "error" || e.msg != "recovered from panic: boom" || e.fields["path"] != "/panic" {
+		t.Errorf("bad panic entry: %+v", e)
+	}
+	if e := entries[1]; e.fields["status"] != http.StatusInternalServerError {
+		t.Errorf("bad access log entry: %+v", e)
+	}
+}
+
+func TestNew_panicAfterWritten(t *testing.T) {
+	rec := newRecorder()
+	h := httplog.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	}), httplog.WithLogger(rec))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	entries := rec.all()
+	if w.Code != http.StatusAccepted || len(entries) != 2 || entries[1].fields["status"] != http.StatusAccepted {
+		t.Fatalf("the access log should report what the client received: %d %+v", w.Code, entries)
+	}
+}
+
+func TestNew_abortHandler(t *testing.T) {
+	rec := newRecorder()
+	h := httplog.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), httplog.WithLogger(rec))
+
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Fatalf("expect http.ErrAbortHandler re-panicked, got %v", r)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+	}()
+	entries := rec.all()
+	if len(entries) != 1 {
+		t.Fatalf("expect an access log entry only, got %+v", entries)
+	}
+	if e := entries[0]; e.level != "info" || e.msg != "request aborted" || e.fields["aborted"] != true {
+		t.Fatalf("the access log should be marked as aborted: %+v", e)
+	}
+	if _, ok := entries[0].fields["status"]; ok {
+		t.Fatalf("the status of an aborted response is unknown: %+v", entries[0])
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright © 2023 Hedzr Yeh.
+
+// Package journald provides a sink which writes to the systemd journal
+// through the native journal protocol, without cgo or libsystemd.
+//
+// A Journal implements log.SystemdLoggerEx and log.SystemdFieldsLogger,
+// so it can be put into hedzr/log by log.FromSystemdLogger:
+//
+//	j, err := journald.New(journald.WithIdentifier("app"))
+//	if err == nil {
+//	    log.SetLogger(log.FromSystemdLogger(j))
+//	}
+//
+// Each entry carries MESSAGE, PRIORITY, SYSLOG_IDENTIFIER, CODE_FILE,
+// CODE_LINE, CODE_FUNC and the fields given by the caller; a field of
+// the caller with one of those names is dropped. An entry too
+// large for a datagram is passed through a sealed memfd (or an unlinked
+// file in /dev/shm), as journald expects.
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hedzr/log"
+	"github.com/hedzr/log/buildtags"
+)
+
+// SocketPath is the path of the native journal socket
+const SocketPath = "/run/systemd/journal/socket"
+
+// Enabled tests if the journal socket exists, that is, the process is
+// running on a host with systemd-journald.
+func Enabled() bool {
+	_, err := os.Stat(SocketPath)
+	return err == nil
+}
+
+// New returns a Journal connected to the journal socket
+func New(opts ...Opt) (*Journal, error) {
+	j := &Journal{path: SocketPath, identifier: filepath.Base(os.Args[0])}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if err := j.dial(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Opt is a functional option for New
+type Opt func(j *Journal)
+
+// WithSocket specifies the path of the journal socket, it's SocketPath
+// by default
+func WithSocket(path string) Opt {
+	return func(j *Journal) {
+		if path != "" {
+			j.path = path
+		}
+	}
+}
+
+// WithIdentifier sets SYSLOG_IDENTIFIER, it's the base name of the
+// executable by default
+func WithIdentifier(name string) Opt {
+	return func(j *Journal) {
+		j.identifier = name
+	}
+}
+
+// WithFields adds the fields to every entry, such as the version of
+// the app. The keys are the journald-style names, see log.JournalFields.
+func WithFields(fields map[string]string) Opt {
+	return func(j *Journal) {
+		if j.fields == nil {
+			j.fields = make(map[string]string)
+		}
+		for k, v := range fields {
+			j.fields[k] = v
+		}
+	}
+}
+
+// Journal writes the entries to the systemd journal
+type Journal struct {
+	path       string
+	identifier string
+	fields     map[string]string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+var (
+	_ log.SystemdLoggerEx     = (*Journal)(nil)
+	_ log.SystemdFieldsLogger = (*Journal)(nil)
+)
+
+func init() {
+	// the callers of a Journal are located through hedzr/log
+	log.RegisterWrapperPackage("github.com/hedzr/log/journald")
+}
+
+func (j *Journal) dial() (err error) {
+	j.conn, err = net.DialUnix("unixgram", nil, &net.UnixAddr{Name: j.path, Net: "unixgram"})
+	return
+}
+
+// Close closes the connection to the journal socket
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conn == nil {
+		return nil
+	}
+	err := j.conn.Close()
+	j.conn = nil
+	return err
+}
+
+// Send writes msg at priority, from 0 (emerg) to 7 (debug), with the
+// fields. The fields with an invalid journald name are dropped.
+func (j *Journal) Send(priority int, msg string, fields map[string]string) error {
+	if buildtags.VeryQuietEnabled {
+		return nil
+	}
+	b := j.encode(priority, msg, fields, caller())
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conn == nil {
+		if err := j.dial(); err != nil {
+			return err
+		}
+	}
+	_, err := j.conn.Write(b)
+	if err != nil && isTooLarge(err) {
+		return sendFile(j.conn, b)
+	}
+	if err != nil {
+		// journald might have been restarted, dial again next time
+		_ = j.conn.Close()
+		j.conn = nil
+	}
+	return err
+}
+
+func (j *Journal) encode(priority int, msg string, fields map[string]string, f runtime.Frame) []byte {
+	var b bytes.Buffer
+	appendField(&b, "MESSAGE", msg)
+	appendField(&b, "PRIORITY", strconv.Itoa(priority))
+	if j.identifier != "" {
+		appendField(&b, "SYSLOG_IDENTIFIER", j.identifier)
+	}
+	if f.PC != 0 {
+		appendField(&b, "CODE_FILE", f.File)
+		appendField(&b, "CODE_LINE", strconv.Itoa(f.Line))
+		appendField(&b, "CODE_FUNC", f.Function)
+	}
+	for _, m := range []map[string]string{j.fields, fields} {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			if validKey(k) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			appendField(&b, k, m[k])
+		}
+	}
+	return b.Bytes()
+}
+
+// appendField writes a field in the native journal protocol: KEY=value
+// in a line, or the key line followed by the little-endian 64-bit size
+// and the value if the value has several lines.
+func appendField(b *bytes.Buffer, key, val string) {
+	b.WriteString(key)
+	if strings.IndexByte(val, '\n') < 0 {
+		b.WriteByte('=')
+		b.WriteString(val)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	_ = binary.Write(b, binary.LittleEndian, uint64(len(val)))
+	b.WriteString(val)
+	b.WriteByte('\n')
+}
+
+// reserved are the fields which encode writes for every entry, a
+// field of the caller must not repeat or replace them
+var reserved = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+}
+
+// validKey tests if key is a valid journald field name for a client:
+// A-Z, 0-9 and '_', not started with '_' or a digit, at most 64 bytes,
+// and not one of the fields written by encode.
+func validKey(key string) bool {
+	if key == "" || len(key) > 64 || key[0] == '_' || key[0] >= '0' && key[0] <= '9' || reserved[key] {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// caller returns the first frame outside hedzr/log and journald
+func caller() runtime.Frame {
+	skip := log.CalcStackFrames(1)
+	pcs := make([]uintptr, 1)
+	if runtime.Callers(skip+2, pcs) == 0 {
+		return runtime.Frame{}
+	}
+	f, _ := runtime.CallersFrames(pcs).Next()
+	return f
+}
+
+func (j *Journal) Emerg(v ...interface{}) error   { return j.Send(0, fmt.Sprint(v...), nil) }
+func (j *Journal) Alert(v ...interface{}) error   { return j.Send(1, fmt.Sprint(v...), nil) }
+func (j *Journal) Crit(v ...interface{}) error    { return j.Send(2, fmt.Sprint(v...), nil) }
+func (j *Journal) Error(v ...interface{}) error   { return j.Send(3, fmt.Sprint(v...), nil) }
+func (j *Journal) Warning(v ...interface{}) error { return j.Send(4, fmt.Sprint(v...), nil) }
+func (j *Journal) Notice(v ...interface{}) error  { return j.Send(5, fmt.Sprint(v...), nil) }
+func (j *Journal) Info(v ...interface{}) error    { return j.Send(6, fmt.Sprint(v...), nil) }
+func (j *Journal) Debug(v ...interface{}) error   { return j.Send(7, fmt.Sprint(v...), nil) }
+
+func (j *Journal) Emergf(format string, a ...interface{}) error {
+	return j.Send(0, fmt.Sprintf(format, a...), nil)
+}
+func (j *Journal) Alertf(format string, a ...interface{}) error {
+	return j.Send(1, fmt.Sprintf(format, a...), nil)
+}
+func (j *Journal) Critf(format string, a ...interface{}) error {
+	return j.Send(2, fmt.Sprintf(format, a...), nil)
+}
+func (j *Journal) Errorf(format string, a ...interface{}) error {
+	return j.Send(3, fmt.Sprintf(format, a...), nil)
+}
+func (j *Journal) Warningf(format string, a ...interface{}) error {
+	return j.Send(4, fmt.Sprintf(format, a...), nil)
+}
+func (j *Journal) Noticef(format string, a ...interface{}) error {
+	return j.Send(5, fmt.Sprintf(format, a...), nil)
+}
+func (j *Journal) Infof(format string, a ...interface{}) error {
+	return j.Send(6, fmt.Sprintf(format, a...), nil)
+}
+func (j *Journal) Debugf(format string, a ...interface{}) error {
+	return j.Send(7, fmt.Sprintf(format, a...), nil)
+}
@@ -0,0 +1,233 @@
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/hedzr/log"
+	"github.com/hedzr/log/buildtags"
+)
+
+// requireOutput skips the tests checking the written entries, since
+// nothing is written in a veryquiet build
+func requireOutput(t *testing.T) {
+	if buildtags.VeryQuietEnabled {
+		t.Skip("nothing is written in a veryquiet build")
+	}
+}
+
+// listen starts a unixgram listener as a stand-in of journald
+func listen(t *testing.T) (conn *net.UnixConn, path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "journald")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path = filepath.Join(dir, "socket")
+	conn, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return conn, path, func() {
+		_ = conn.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// receive reads an entry, from a datagram or a passed file
+func receive(t *testing.T, conn *net.UnixConn) map[string]string {
+	buf, oob := make([]byte, 1<<16), make([]byte, 64)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := buf[:n]
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err != nil || len(msgs) != 1 {
+			t.Fatalf("bad control message: %v", err)
+		}
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		if err != nil || len(fds) != 1 {
+			t.Fatalf("bad rights: %v", err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "journal")
+		defer f.Close()
+		if _, err = f.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if b, err = ioutil.ReadAll(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return parse(t, b)
+}
+
+// parse decodes the native journal protocol
+func parse(t *testing.T, b []byte) map[string]string {
+	m := make(map[string]string)
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			t.Fatalf("bad entry: %q", b)
+		}
+		line := string(b[:i])
+		b = b[i+1:]
+		if eq := strings.IndexByte(line, '='); eq >= 0 {
+			m[line[:eq]] = line[eq+1:]
+			continue
+		}
+		size := binary.LittleEndian.Uint64(b)
+		m[line] = string(b[8 : 8+size])
+		b = b[8+size+1:]
+	}
+	return m
+}
+
+func TestJournal_Send(t *testing.T) {
+	requireOutput(t)
+	conn, path, cleanup := listen(t)
+	defer cleanup()
+
+	j, err := New(WithSocket(path), WithIdentifier("app"), WithFields(map[string]string{"VERSION": "1.0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	if err = j.Send(4, "disk\nis full", map[string]string{"DISK": "/dev/sda", "bad-key": "x", "_PID": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	m := receive(t, conn)
+	want := map[string]string{
+		"MESSAGE": "disk\nis full", "PRIORITY": "4", "SYSLOG_IDENTIFIER": "app",
+		"VERSION": "1.0", "DISK": "/dev/sda",
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %q, want %q", k, m[k], v)
+		}
+	}
+	if _, ok := m["_PID"]; ok || len(m) != len(want)+3 {
+		t.Errorf("the invalid keys should be dropped: %v", m)
+	}
+	if !strings.HasSuffix(m["CODE_FILE"], "journald_test.go") || m["CODE_LINE"] == "" || !strings.HasSuffix(m["CODE_FUNC"], "TestJournal_Send") {
+		t.Errorf("bad caller: %v", m)
+	}
+}
+
+func TestJournal_reservedKeys(t *testing.T) {
+	requireOutput(t)
+	conn, path, cleanup := listen(t)
+	defer cleanup()
+
+	j, err := New(WithSocket(path), WithIdentifier("app"), WithFields(map[string]string{"SYSLOG_IDENTIFIER": "other"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	fields := map[string]string{
+		"MESSAGE": "fake", "PRIORITY": "0", "CODE_FILE": "x.go", "CODE_LINE": "1", "CODE_FUNC": "x", "USER": "u",
+	}
+	if err = j.Send(6, "real", fields); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 1<<16)
+	n, err := conn.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := make(map[string]int)
+	for _, line := range strings.Split(string(b[:n]), "\n") {
+		if eq := strings.IndexByte(line, '='); eq > 0 {
+			count[line[:eq]]++
+		}
+	}
+	for k, c := range count {
+		if c > 1 {
+			t.Errorf("%s is written %d times", k, c)
+		}
+	}
+	m := parse(t, b[:n])
+	if m["MESSAGE"] != "real" || m["PRIORITY"] != "6" || m["SYSLOG_IDENTIFIER"] != "app" || m["CODE_FILE"] == "x.go" || m["USER"] != "u" {
+		t.Errorf("the reserved fields should not be replaced: %v", m)
+	}
+}
+
+func TestJournal_fromSystemdLogger(t *testing.T) {
+	requireOutput(t)
+	conn, path, cleanup := listen(t)
+	defer cleanup()
+
+	j, err := New(WithSocket(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	saved := log.GetLogger()
+	log.SetLogger(log.NewDummyLogger())
+	defer log.SetLogger(saved)
+
+	l := log.FromSystemdLogger(j)
+	l.SetLevel(log.DebugLevel)
+	l.(log.SL).With("user.id", 7).Debugf("hello %s", "world")
+	m := receive(t, conn)
+	if m["MESSAGE"] != "hello world" || m["PRIORITY"] != "7" || m["USER_ID"] != "7" {
+		t.Fatalf("bad entry: %v", m)
+	}
+	if !strings.HasSuffix(m["CODE_FILE"], "journald_test.go") {
+		t.Fatalf("the caller should be located through hedzr/log: %v", m)
+	}
+}
+
+func TestJournal_large(t *testing.T) {
+	requireOutput(t)
+	conn, path, cleanup := listen(t)
+	defer cleanup()
+
+	j, err := New(WithSocket(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	msg := strings.Repeat("x", 4<<20)
+	if err = j.Info(msg); err != nil {
+		t.Fatal(err)
+	}
+	if m := receive(t, conn); m["MESSAGE"] != msg || m["PRIORITY"] != "6" {
+		t.Fatalf("bad large entry: %d bytes", len(m["MESSAGE"]))
+	}
+}
+
+func TestNew_noSocket(t *testing.T) {
+	if _, err := New(WithSocket(filepath.Join(os.TempDir(), "no-such-journal-socket"))); err == nil {
+		t.Fatal("expect an error")
+	}
+}
+
+func TestMemfd_sealed(t *testing.T) {
+	f, err := memfd([]byte("entry"))
+	if err == syscall.ENOSYS {
+		t.Skip("memfd_create is not available")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err = f.Write([]byte("more")); err == nil {
+		t.Fatal("a sealed memfd should not be writable")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright © 2023 Hedzr Yeh.
+
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// isTooLarge tests if a datagram was rejected for its size
+func isTooLarge(err error) bool {
+	if oe, ok := err.(*net.OpError); ok {
+		err = oe.Err
+	}
+	if se, ok := err.(*os.SyscallError); ok {
+		err = se.Err
+	}
+	return err == syscall.EMSGSIZE || err == syscall.ENOBUFS
+}
+
+// sendFile writes b into a sealed memfd, or an unlinked file in
+// /dev/shm, and passes its descriptor to journald
+func sendFile(conn *net.UnixConn, b []byte) error {
+	f, err := memfd(b)
+	if err != nil {
+		if f, err = shmFile(b); err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+
+	// WriteMsgUnix refuses a connected datagram socket
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	rights := syscall.UnixRights(int(f.Fd()))
+	if e := rc.Write(func(fd uintptr) bool {
+		err = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return err != syscall.EAGAIN
+	}); e != nil {
+		return e
+	}
+	return err
+}
+
+const (
+	mfdCloexec      = 0x1
+	mfdAllowSealing = 0x2
+	fAddSeals       = 1033
+	fSealAll        = 0x1 | 0x2 | 0x4 | 0x8 // seal, shrink, grow, write
+)
+
+// memfdCreate is the number of memfd_create(2), which is missing in
+// package syscall
+var memfdCreate = map[string]uintptr{
+	"386": 356, "amd64": 319, "arm": 385, "arm64": 279, "riscv64": 279,
+	"ppc64": 360, "ppc64le": 360, "s390x": 350,
+}[runtime.GOARCH]
+
+func memfd(b []byte) (*os.File, error) {
+	if memfdCreate == 0 {
+		return nil, syscall.ENOSYS
+	}
+	name := []byte("journal\x00")
+	fd, _, errno := syscall.Syscall(memfdCreate, uintptr(unsafe.Pointer(&name[0])), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	f := os.NewFile(fd, "memfd:journal")
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return nil, err
+	}
+	// journald accepts a memfd from an unprivileged process only if it is sealed
+	if _, _, errno = syscall.Syscall(syscall.SYS_FCNTL, fd, fAddSeals, fSealAll); errno != 0 {
+		f.Close()
+		return nil, errno
+	}
+	return f, nil
+}
+
+func shmFile(b []byte) (*os.File, error) {
+	f, err := ioutil.TempFile("/dev/shm", "journal.")
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(f.Name())
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,13 @@
+// Copyright © 2023 Hedzr Yeh.
+
+//go:build !linux
+// +build !linux
+
+package journald
+
+import "net"
+
+// isTooLarge is always false since journald runs on linux only
+func isTooLarge(err error) bool { return false }
+
+func sendFile(conn *net.UnixConn, b []byte) error { return nil }
@@ -1,48 +1,117 @@
 package log
 
-// AsSystemdLogger _
-func AsSystemdLogger(l L) SystemdLogger {
-	return &asl{
-		L: l,
+import "fmt"
+
+// AsSystemdLogger converts a L to SystemdLogger, so that the code
+// written for the system log can log through hedzr/log.
+//
+// The returned logger satisfies the Logger interface of
+// github.com/kardianos/service too, which has the same methods as
+// SystemdLogger, so a daemon wrapped by that library can log through
+// hedzr/log. It implements SystemdLoggerEx as well.
+//
+// Each priority, from 0 (emerg) to 7 (debug), is mapped to a Level:
+// PanicLevel, FatalLevel, CriticalLevel, ErrorLevel, WarnLevel,
+// NoticeLevel, InfoLevel and DebugLevel. Use WithPriorityLevel to
+// change the mapping. The entries are never exiting or panicking,
+// even for PanicLevel and FatalLevel.
+//
+// If l implements OL, such as the std logger, the errors of its output
+// device are returned. Otherwise, nil is returned since L reports
+// nothing.
+func AsSystemdLogger(l L, opts ...AsSystemdOpt) SystemdLogger {
+	a := &asl{
+		L:      l,
+		levels: [8]Level{PanicLevel, FatalLevel, CriticalLevel, ErrorLevel, WarnLevel, NoticeLevel, InfoLevel, DebugLevel},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// AsSystemdOpt is a functional option for AsSystemdLogger
+type AsSystemdOpt func(a *asl)
+
+// WithPriorityLevel maps the syslog priority, from 0 (emerg) to 7
+// (debug), to lvl. For example, WithPriorityLevel(6, DebugLevel)
+// hides the Info entries of a chatty library unless in debug mode.
+func WithPriorityLevel(priority int, lvl Level) AsSystemdOpt {
+	return func(a *asl) {
+		if priority >= 0 && priority < len(a.levels) {
+			a.levels[priority] = lvl
+		}
 	}
 }
 
 type asl struct {
 	L
+	levels [8]Level
 }
 
-func (a *asl) Error(v ...interface{}) error {
-	a.L.Error(v...)
+// log writes msg at the level mapped from priority, through the
+// richest interface implemented by a.L
+func (a *asl) log(priority int, msg string) error {
+	lvl := a.levels[priority&7]
+	if ol, ok := a.L.(OL); ok {
+		return ol.Output(lvl, msg)
+	}
+	if ll, ok := a.L.(LL); ok {
+		ll.Logf(lvl, "%s", msg)
+		return nil
+	}
+	switch b := lvl.builtin(); {
+	case b <= ErrorLevel:
+		a.L.Error(msg)
+	case b == WarnLevel:
+		a.L.Warn(msg)
+	case b == InfoLevel:
+		a.L.Info(msg)
+	case b == DebugLevel:
+		a.L.Debug(msg)
+	default:
+		a.L.Trace(msg)
+	}
 	return nil
 }
 
-func (a *asl) Warning(v ...interface{}) error {
-	a.L.Warn(v...)
-	return nil
+func (a *asl) Emerg(v ...interface{}) error   { return a.log(0, fmt.Sprint(v...)) }
+func (a *asl) Alert(v ...interface{}) error   { return a.log(1, fmt.Sprint(v...)) }
+func (a *asl) Crit(v ...interface{}) error    { return a.log(2, fmt.Sprint(v...)) }
+func (a *asl) Error(v ...interface{}) error   { return a.log(3, fmt.Sprint(v...)) }
+func (a *asl) Warning(v ...interface{}) error { return a.log(4, fmt.Sprint(v...)) }
+func (a *asl) Notice(v ...interface{}) error  { return a.log(5, fmt.Sprint(v...)) }
+func (a *asl) Info(v ...interface{}) error    { return a.log(6, fmt.Sprint(v...)) }
+func (a *asl) Debug(v ...interface{}) error   { return a.log(7, fmt.Sprint(v...)) }
+
+func (a *asl) Emergf(format string, args ...interface{}) error {
+	return a.log(0, fmt.Sprintf(format, args...))
 }
 
-func (a *asl) Info(v ...interface{}) error {
-	a.L.Info(v...)
-	return nil
+func (a *asl) Alertf(format string, args ...interface{}) error {
+	return a.log(1, fmt.Sprintf(format, args...))
+}
+
+func (a *asl) Critf(format string, args ...interface{}) error {
+	return a.log(2, fmt.Sprintf(format, args...))
 }
 
 func (a *asl) Errorf(format string, args ...interface{}) error {
-	if lf, ok := a.L.(LF); ok {
-		lf.Errorf(format, args...)
-	}
-	return nil
+	return a.log(3, fmt.Sprintf(format, args...))
 }
 
 func (a *asl) Warningf(format string, args ...interface{}) error {
-	if lf, ok := a.L.(LF); ok {
-		lf.Warnf(format, args...)
-	}
-	return nil
+	return a.log(4, fmt.Sprintf(format, args...))
+}
+
+func (a *asl) Noticef(format string, args ...interface{}) error {
+	return a.log(5, fmt.Sprintf(format, args...))
 }
 
 func (a *asl) Infof(format string, args ...interface{}) error {
-	if lf, ok := a.L.(LF); ok {
-		lf.Infof(format, args...)
-	}
-	return nil
+	return a.log(6, fmt.Sprintf(format, args...))
+}
+
+func (a *asl) Debugf(format string, args ...interface{}) error {
+	return a.log(7, fmt.Sprintf(format, args...))
 }
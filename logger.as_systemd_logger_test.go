@@ -0,0 +1,93 @@
+package log
+
+import (
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// serviceLogger is the Logger interface of github.com/kardianos/service
+type serviceLogger interface {
+	Error(v ...interface{}) error
+	Warning(v ...interface{}) error
+	Info(v ...interface{}) error
+
+	Errorf(format string, a ...interface{}) error
+	Warningf(format string, a ...interface{}) error
+	Infof(format string, a ...interface{}) error
+}
+
+var _ serviceLogger = AsSystemdLogger(nil)
+
+// recordL records the levels of the entries written to a L
+type recordL struct {
+	L
+	lvls []Level
+}
+
+func (r *recordL) Trace(args ...interface{}) { r.lvls = append(r.lvls, TraceLevel) }
+func (r *recordL) Debug(args ...interface{}) { r.lvls = append(r.lvls, DebugLevel) }
+func (r *recordL) Info(args ...interface{})  { r.lvls = append(r.lvls, InfoLevel) }
+func (r *recordL) Warn(args ...interface{})  { r.lvls = append(r.lvls, WarnLevel) }
+func (r *recordL) Error(args ...interface{}) { r.lvls = append(r.lvls, ErrorLevel) }
+
+// recordLL is a recordL which implements LL
+type recordLL struct{ recordL }
+
+func (r *recordLL) Logf(lvl Level, msg string, args ...interface{}) { r.lvls = append(r.lvls, lvl) }
+
+func TestAsSystemdLogger_levels(t *testing.T) {
+	ll := &recordLL{}
+	sl := AsSystemdLogger(ll, WithPriorityLevel(6, DebugLevel)).(SystemdLoggerEx)
+	_ = sl.Emerg("emerg")
+	_ = sl.Alertf("alert")
+	_ = sl.Crit("crit")
+	_ = sl.Errorf("error")
+	_ = sl.Warning("warning")
+	_ = sl.Noticef("notice")
+	_ = sl.Info("info")
+	_ = sl.Debugf("debug")
+	want := []Level{PanicLevel, FatalLevel, CriticalLevel, ErrorLevel, WarnLevel, NoticeLevel, DebugLevel, DebugLevel}
+	if !reflect.DeepEqual(ll.lvls, want) {
+		t.Fatalf("bad levels %v, want %v", ll.lvls, want)
+	}
+
+	l := &recordL{}
+	sl = AsSystemdLogger(l).(SystemdLoggerEx)
+	_ = sl.Crit("crit")
+	_ = sl.Notice("notice")
+	_ = sl.Warningf("warning")
+	if want = []Level{ErrorLevel, InfoLevel, WarnLevel}; !reflect.DeepEqual(l.lvls, want) {
+		t.Fatalf("bad levels %v, want %v", l.lvls, want)
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func TestAsSystemdLogger_errors(t *testing.T) {
+	requireOutput(t)
+	log.SetOutput(failWriter{})
+	defer log.SetOutput(os.Stderr)
+
+	sl := AsSystemdLogger(AsL(newStdLoggerWith(InfoLevel)))
+	if err := sl.Infof("lost %d", 1); err != io.ErrClosedPipe {
+		t.Fatalf("expect the write error, got %v", err)
+	}
+	if err := sl.(SystemdLoggerEx).Debug("hidden"); err != nil {
+		t.Fatalf("expect nil for a hidden entry, got %v", err)
+	}
+
+	out := captureStdOutput(func() {
+		if err := sl.(SystemdLoggerEx).Emerg("emerg"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "emerg") || !strings.Contains(out, "logger.as_systemd_logger_test.go:") {
+		t.Fatalf("expect an entry without panicking: %q", out)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConfigFromEnv returns a LoggerConfig with the defaults of
+// NewLoggerConfig, which are overridden by the environment variables
+// named PREFIX_LOG_*, for example, for prefix "app":
+//
+//	APP_LOG_ENABLED              bool
+//	APP_LOG_BACKEND              zap, sugar, logrus, ...
+//	APP_LOG_LEVEL                a level name, such as debug
+//	APP_LOG_FORMAT               text or json
+//	APP_LOG_TARGET               console, file or console+file
+//	APP_LOG_DIR                  the directory for target file
+//	APP_LOG_ALL_TO_ERROR_DEVICE  bool
+//	APP_LOG_MAXSIZE              megabytes, >= 0
+//	APP_LOG_MAXAGE               days, >= 0
+//	APP_LOG_MAXBACKUPS           >= 0
+//	APP_LOG_LOCALTIME            bool
+//	APP_LOG_COMPRESS             bool
+//	APP_LOG_EXTRA_SKIP           int
+//	APP_LOG_SHORT_TIMESTAMP      bool
+//	APP_LOG_TIMESTAMP_FORMAT     string
+//	APP_LOG_CALLER               short, long, func, package or none
+//	APP_LOG_STACKTRACE           a level name or off
+//	APP_LOG_MULTILINE            raw, indent, escape or split
+//
+// The variables are named LOG_* if prefix is empty. An unset or empty
+// variable keeps the default value. DebugMode and TraceMode follow the
+// resulting level.
+//
+// An invalid value is reported by an *EnvError which names the
+// variable, such as:
+//
+//	APP_LOG_LEVEL="loud": not a valid logging Level: "loud"
+func ConfigFromEnv(prefix string, opts ...Opt) (*LoggerConfig, error) {
+	lc := NewLoggerConfig(opts...)
+	if prefix != "" {
+		prefix = strings.ToUpper(prefix) + "_"
+	}
+	for _, v := range envVars {
+		name := prefix + "LOG_" + v.name
+		val, ok := os.LookupEnv(name)
+		if !ok || val == "" {
+			continue
+		}
+		err := setField(v.field(lc), val)
+		if err == nil && v.check != nil {
+			err = v.check(val)
+		}
+		if err != nil {
+			return nil, &EnvError{Name: name, Value: val, Err: err}
+		}
+	}
+
+	l, _ := ParseLevel(lc.Level)
+	lc.DebugMode, lc.TraceMode = l.Allows(DebugLevel), l.Allows(TraceLevel)
+	return lc, nil
+}
+
+// EnvError reports an invalid value of an environment variable
+type EnvError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+func (e *EnvError) Error() string { return fmt.Sprintf("%s=%q: %v", e.Name, e.Value, e.Err) }
+
+// Unwrap returns the underlying error
+func (e *EnvError) Unwrap() error { return e.Err }
+
+// envVars maps the variables to the fields of LoggerConfig, with an
+// optional check for the value
+var envVars = []struct {
+	name  string
+	field func(lc *LoggerConfig) interface{} // returns *string, *bool or *int
+	check func(val string) error
+}{
+	{"ENABLED", func(lc *LoggerConfig) interface{} { return &lc.Enabled }, nil},
+	{"BACKEND", func(lc *LoggerConfig) interface{} { return &lc.Backend }, nil},
+	{"LEVEL", func(lc *LoggerConfig) interface{} { return &lc.Level }, checkLevel},
+	{"FORMAT", func(lc *LoggerConfig) interface{} { return &lc.Format }, oneOf(validFormats)},
+	{"TARGET", func(lc *LoggerConfig) interface{} { return &lc.Target }, oneOf(validTargets)},
+	{"DIR", func(lc *LoggerConfig) interface{} { return &lc.Directory }, nil},
+	{"ALL_TO_ERROR_DEVICE", func(lc *LoggerConfig) interface{} { return &lc.AllToErrorDevice }, nil},
+	{"MAXSIZE", func(lc *LoggerConfig) interface{} { return &lc.MaxSize }, checkNonNegative},
+	{"MAXAGE", func(lc *LoggerConfig) interface{} { return &lc.MaxAge }, checkNonNegative},
+	{"MAXBACKUPS", func(lc *LoggerConfig) interface{} { return &lc.MaxBackups }, checkNonNegative},
+	{"LOCALTIME", func(lc *LoggerConfig) interface{} { return &lc.LocalTime }, nil},
+	{"COMPRESS", func(lc *LoggerConfig) interface{} { return &lc.Compress }, nil},
+	{"EXTRA_SKIP", func(lc *LoggerConfig) interface{} { return &lc.ExtraSkip }, nil},
+	{"SHORT_TIMESTAMP", func(lc *LoggerConfig) interface{} { return &lc.ShortTimestamp }, nil},
+	{"TIMESTAMP_FORMAT", func(lc *LoggerConfig) interface{} { return &lc.TimestampFormat }, nil},
+	{"CALLER", func(lc *LoggerConfig) interface{} { return &lc.CallerFormat }, oneOf(validCallerFormats)},
+	{"STACKTRACE", func(lc *LoggerConfig) interface{} { return &lc.StacktraceLevel }, checkLevel},
+	{"MULTILINE", func(lc *LoggerConfig) interface{} { return &lc.Multiline }, oneOf(validMultilineModes)},
+}
+
+// setField parses val into the field pointed by p
+func setField(p interface{}, val string) (err error) {
+	switch v := p.(type) {
+	case *string:
+		*v = val
+	case *bool:
+		*v, err = strconv.ParseBool(val)
+	case *int:
+		*v, err = strconv.Atoi(val)
+	}
+	return
+}
+
+var (
+	validFormats        = []string{"text", "json"}
+	validTargets        = []string{"console", "file", "console+file"}
+	validCallerFormats  = []string{CallerShort, CallerLong, CallerFunc, CallerPackage, CallerNone}
+	validMultilineModes = []string{MultilineRaw, MultilineIndent, MultilineEscape, MultilineSplit}
+)
+
+func checkLevel(s string) error {
+	_, err := ParseLevel(s)
+	return err
+}
+
+func oneOf(valid []string) func(s string) error {
+	return func(s string) error {
+		for _, v := range valid {
+			if strings.EqualFold(s, v) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expecting one of %s", strings.Join(valid, ", "))
+	}
+}
+
+func checkNonNegative(s string) error {
+	if n, err := strconv.Atoi(s); err != nil || n < 0 {
+		return fmt.Errorf("expecting a non-negative integer")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func setEnv(t *testing.T, kv map[string]string) func() {
+	for k, v := range kv {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() {
+		for k := range kv {
+			_ = os.Unsetenv(k)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	defer setEnv(t, map[string]string{
+		"APP_LOG_LEVEL":     "debug",
+		"APP_LOG_FORMAT":    "json",
+		"APP_LOG_TARGET":    "file",
+		"APP_LOG_DIR":       "/tmp/app",
+		"APP_LOG_MAXSIZE":   "64",
+		"APP_LOG_COMPRESS":  "false",
+		"APP_LOG_CALLER":    "func",
+		"APP_LOG_MULTILINE": "",
+	})()
+
+	lc, err := ConfigFromEnv("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lc.Level != "debug" || !lc.DebugMode || lc.TraceMode || lc.Format != "json" || lc.Target != "file" ||
+		lc.Directory != "/tmp/app" || lc.MaxSize != 64 || lc.Compress || lc.CallerFormat != CallerFunc {
+		t.Fatalf("bad config: %+v", lc)
+	}
+	if lc.MaxBackups != 3 || lc.Multiline != "" {
+		t.Fatalf("the unset or empty variables should keep the defaults: %+v", lc)
+	}
+}
+
+func TestConfigFromEnv_invalid(t *testing.T) {
+	for name, val := range map[string]string{
+		"APP_LOG_LEVEL":      "loud",
+		"APP_LOG_FORMAT":     "xml",
+		"APP_LOG_TARGET":     "printer",
+		"APP_LOG_MAXSIZE":    "-1",
+		"APP_LOG_MAXAGE":     "a week",
+		"APP_LOG_COMPRESS":   "sure",
+		"APP_LOG_STACKTRACE": "always",
+	} {
+		restore := setEnv(t, map[string]string{name: val})
+		_, err := ConfigFromEnv("app")
+		restore()
+
+		ee, ok := err.(*EnvError)
+		if !ok || ee.Name != name || !strings.HasPrefix(err.Error(), name+"=") {
+			t.Errorf("%s=%q: expect an EnvError naming the variable, got %v", name, val, err)
+		}
+	}
+}
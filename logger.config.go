@@ -7,15 +7,19 @@ import (
 type (
 	// LoggerConfig is used for creating a minimal logger with no more dependencies
 	LoggerConfig struct {
-		Enabled          bool
-		Backend          string // zap, sugar, logrus
-		Level            string // level
-		Format           string // text, json, ...
-		Target           string // console, file, console+file
-		Directory        string // logdir, for file
-		AllToErrorDevice bool   //
-		DebugMode        bool   `json:"-" yaml:"-"`
-		TraceMode        bool   `json:"-" yaml:"-"`
+		Enabled          bool   `json:"enabled" yaml:"enabled"`
+		Backend          string `json:"backend" yaml:"backend"`     // zap, sugar, logrus
+		Level            string `json:"level" yaml:"level"`         // level
+		Format           string `json:"format" yaml:"format"`       // text, json, ...
+		Target           string `json:"target" yaml:"target"`       // console, file, console+file
+		Directory        string `json:"directory" yaml:"directory"` // logdir, for file
+		AllToErrorDevice bool   `json:"alltoerrordevice" yaml:"alltoerrordevice"`
+
+		// DebugMode and TraceMode follow Level generally. If one of them
+		// is true in a config file, LoadConfig raises Level to debug or
+		// trace.
+		DebugMode bool `json:"debug,omitempty" yaml:"debug,omitempty"`
+		TraceMode bool `json:"trace,omitempty" yaml:"trace,omitempty"`
 
 		// the following options are copied from zap rotator
 
@@ -44,9 +48,26 @@ type (
 		// using gzip. The default is not to perform compression.
 		Compress bool `json:"compress" yaml:"compress"`
 
-		ExtraSkip       int
-		ShortTimestamp  bool   // remove year field for a shorter timestamp stringify
-		TimestampFormat string // never used
+		ExtraSkip       int    `json:"extraskip" yaml:"extraskip"`
+		ShortTimestamp  bool   `json:"shorttimestamp" yaml:"shorttimestamp"`   // remove year field for a shorter timestamp stringify
+		TimestampFormat string `json:"timestampformat" yaml:"timestampformat"` // never used
+
+		// CallerFormat tells the built-in formatter how to report the
+		// caller: short (file.go:12), long (/path/to/file.go:12),
+		// func (pkg.Function:12), package (github.com/x/pkg/file.go:12)
+		// or none. The default is short.
+		CallerFormat string `json:"caller" yaml:"caller"`
+
+		// StacktraceLevel enables capturing the stack trace for the
+		// entries at or above this level, such as "error". The stack
+		// trace is empty if it's empty (by default) or "off".
+		StacktraceLevel string `json:"stacktrace" yaml:"stacktrace"`
+
+		// Multiline tells the text formatter how to print a message
+		// with several lines, such as the stderr output of a command:
+		// raw (by default), indent, escape or split. See MultilineRaw,
+		// MultilineIndent, MultilineEscape and MultilineSplit.
+		Multiline string `json:"multiline" yaml:"multiline"`
 	}
 )
 
@@ -67,11 +88,16 @@ func NewLoggerConfigWith(enabled bool, backend, level string, opts ...Opt) *Logg
 
 	var l Level
 	l, _ = ParseLevel(level)
-	states.Env().SetDebugMode(l >= DebugLevel)
-	states.Env().SetTraceMode(l >= TraceLevel)
+	states.Env().SetDebugMode(l.Allows(DebugLevel))
+	states.Env().SetTraceMode(l.Allows(TraceLevel))
 
 	dm, tm = states.Env().GetDebugMode(), states.Env().GetTraceMode()
+	return newLoggerConfig(enabled, backend, level, dm, tm, opts...)
+}
 
+// newLoggerConfig returns a default LoggerConfig without reading or
+// changing states
+func newLoggerConfig(enabled bool, backend, level string, dm, tm bool, opts ...Opt) *LoggerConfig {
 	lc := &LoggerConfig{
 		Enabled:   enabled,
 		Backend:   backend,
@@ -90,6 +116,7 @@ func NewLoggerConfigWith(enabled bool, backend, level string, opts ...Opt) *Logg
 		ExtraSkip:       -1,
 		ShortTimestamp:  false, //
 		TimestampFormat: "",
+		CallerFormat:    CallerShort,
 	}
 
 	for _, opt := range opts {
@@ -117,3 +144,28 @@ func WithExtraSkip(extraSkip int) Opt {
 		lc.ExtraSkip = extraSkip
 	}
 }
+
+// WithCaller specifies how the caller is reported, see CallerShort,
+// CallerLong, CallerFunc, CallerPackage and CallerNone.
+func WithCaller(callerFormat string) Opt {
+	return func(lc *LoggerConfig) {
+		lc.CallerFormat = callerFormat
+	}
+}
+
+// WithStacktraceLevel enables capturing the stack trace for the entries
+// at or above lvl.
+func WithStacktraceLevel(lvl string) Opt {
+	return func(lc *LoggerConfig) {
+		lc.StacktraceLevel = lvl
+	}
+}
+
+// WithMultiline specifies how a message with several lines is printed
+// by the text formatter, see MultilineRaw, MultilineIndent,
+// MultilineEscape and MultilineSplit.
+func WithMultiline(mode string) Opt {
+	return func(lc *LoggerConfig) {
+		lc.Multiline = mode
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadConfig reads a YAML (.yaml, .yml) or JSON (.json) file, decodes
+// it over the defaults of NewLoggerConfig and validates the result, so
+// a file needs only the fields to be changed:
+//
+//	level: debug
+//	format: json
+//	target: file
+//	directory: /var/log/app
+//	maxsize: 64
+//
+// The keys are the json/yaml tags of LoggerConfig. If debug or trace
+// is true in the file, Level is raised to debug or trace.
+func LoadConfig(path string, opts ...Opt) (*LoggerConfig, error) {
+	return decodeConfig(path, NewLoggerConfig(opts...))
+}
+
+// parseConfig is LoadConfig without reading or changing states, the
+// level is info unless the file specifies it
+func parseConfig(path string, opts ...Opt) (*LoggerConfig, error) {
+	return decodeConfig(path, newLoggerConfig(true, "sugar", "info", false, false, opts...))
+}
+
+// decodeConfig reads the file at path over the defaults in lc
+func decodeConfig(path string, lc *LoggerConfig) (*LoggerConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.DebugMode, lc.TraceMode = false, false
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, lc)
+	case ".json":
+		err = json.Unmarshal(data, lc)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config file type, expecting .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if lc.TraceMode {
+		lc.Level = "trace"
+	} else if l, e := ParseLevel(lc.Level); lc.DebugMode && e == nil && !l.Allows(DebugLevel) {
+		lc.Level = "debug"
+	}
+	if err = lc.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	l, _ := ParseLevel(lc.Level)
+	lc.DebugMode, lc.TraceMode = l.Allows(DebugLevel), l.Allows(TraceLevel)
+	return lc, nil
+}
+
+// Validate checks the level names, the formats, the targets and the
+// numeric ranges of lc. The error names the offending field by its
+// json/yaml key.
+func (lc *LoggerConfig) Validate() error {
+	checks := []struct {
+		key   string
+		val   string
+		check func(string) error
+	}{
+		{"level", lc.Level, checkLevel},
+		{"format", lc.Format, optional(oneOf(validFormats))},
+		{"target", lc.Target, optional(oneOf(validTargets))},
+		{"caller", lc.CallerFormat, optional(oneOf(validCallerFormats))},
+		{"stacktrace", lc.StacktraceLevel, optional(checkLevel)},
+		{"multiline", lc.Multiline, optional(oneOf(validMultilineModes))},
+		{"maxsize", strconv.Itoa(lc.MaxSize), checkNonNegative},
+		{"maxage", strconv.Itoa(lc.MaxAge), checkNonNegative},
+		{"maxbackups", strconv.Itoa(lc.MaxBackups), checkNonNegative},
+	}
+	for _, c := range checks {
+		if err := c.check(c.val); err != nil {
+			return fmt.Errorf("invalid %s %q: %v", c.key, c.val, err)
+		}
+	}
+	if strings.Contains(strings.ToLower(lc.Target), "file") && lc.Directory == "" {
+		return fmt.Errorf("invalid directory: required by target %q", lc.Target)
+	}
+	return nil
+}
+
+// optional accepts an empty value, which means the default
+func optional(check func(string) error) func(string) error {
+	return func(s string) error {
+		if s == "" {
+			return nil
+		}
+		return check(s)
+	}
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTempConfig(t *testing.T, name, content string) (path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "log-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path = filepath.Join(dir, name)
+	if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestLoadConfig(t *testing.T) {
+	for name, content := range map[string]string{
+		"log.yaml": "level: warn\nformat: json\ntarget: file\ndirectory: /tmp/app\nmaxsize: 64\ncompress: false\n",
+		"log.json": `{"level": "warn", "format": "json", "target": "file", "directory": "/tmp/app", "maxsize": 64, "compress": false}`,
+	} {
+		path, cleanup := writeTempConfig(t, name, content)
+		lc, err := LoadConfig(path)
+		cleanup()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if lc.Level != "warn" || lc.Format != "json" || lc.Target != "file" || lc.Directory != "/tmp/app" || lc.MaxSize != 64 || lc.Compress {
+			t.Fatalf("%s: bad config: %+v", name, lc)
+		}
+		if !lc.Enabled || lc.MaxBackups != 3 || lc.CallerFormat != CallerShort {
+			t.Fatalf("%s: the defaults should be kept: %+v", name, lc)
+		}
+	}
+}
+
+func TestLoadConfig_debugMode(t *testing.T) {
+	path, cleanup := writeTempConfig(t, "log.yml", "level: info\ndebug: true\n")
+	defer cleanup()
+	lc, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lc.Level != "debug" || !lc.DebugMode || lc.TraceMode {
+		t.Fatalf("debug: true should raise the level: %+v", lc)
+	}
+}
+
+func TestLoadConfig_invalid(t *testing.T) {
+	for content, want := range map[string]string{
+		"level: loud\n":                   `invalid level "loud"`,
+		"format: xml\n":                   `invalid format "xml"`,
+		"maxage: -1\n":                    `invalid maxage "-1"`,
+		"target: file\ndirectory: \"\"\n": "invalid directory",
+		"level: [\n":                      "log.yaml: yaml:",
+	} {
+		path, cleanup := writeTempConfig(t, "log.yaml", content)
+		_, err := LoadConfig(path)
+		cleanup()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expect error %q, got %v", content, want, err)
+		}
+	}
+
+	path, cleanup := writeTempConfig(t, "log.toml", "level = 'info'\n")
+	defer cleanup()
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("expect an error for the unsupported file type")
+	}
+}
+
+func TestLoggerConfig_tags(t *testing.T) {
+	b, err := json.Marshal(NewLoggerConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"enabled":`, `"backend":`, `"level":`, `"format":`, `"target":`, `"directory":`, `"extraskip":`} {
+		if !strings.Contains(string(b), key) {
+			t.Errorf("expect %s in %s", key, b)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatchConfig polls the config file at path, which is loaded like
+// LoadConfig, and re-applies it to the package-level logger once it
+// has been changed. No inotify or other dependency is required. The
+// file is parsed without changing the debug and trace modes of states.
+//
+// A change is applied as a whole or not at all: an unreadable or
+// invalid file, or a change which cannot be applied, is reported by a
+// warning and the current logger is kept as is. If only the level
+// changed, the level of the current logger is set. Otherwise, the
+// change is applied in place by the current logger if it implements
+// CL, such as the std logger which applies the level, format, caller,
+// stacktrace and multiline settings but no target or rotation ones.
+//
+// With WithWatchBuilder, a new logger is built from the new config
+// instead, its level is set, and then it replaces the package-level
+// logger atomically. The changed fields are logged as "key: old ->
+// new".
+//
+// The file is loaded at first as the baseline, but not applied. The
+// returned stop function ends the polling, it can be called more than
+// once and is suitable for closers.RegisterCloseFns:
+//
+//	stop, err := log.WatchConfig("/etc/app/log.yaml", log.WithWatchInterval(5*time.Second))
+//	if err == nil {
+//	    closers.RegisterCloseFns(stop)
+//	}
+func WatchConfig(path string, opts ...WatchOpt) (stop func(), err error) {
+	w, err := newConfigWatcher(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w.stop, nil
+}
+
+func newConfigWatcher(path string, opts ...WatchOpt) (w *configWatcher, err error) {
+	w = &configWatcher{path: path, interval: 2 * time.Second, done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.stamp, err = statStamp(path); err != nil {
+		return nil, err
+	}
+	if w.current, err = parseConfig(path, w.lcOpts...); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// WatchOpt is a functional option for WatchConfig
+type WatchOpt func(w *configWatcher)
+
+// WithWatchInterval sets the polling interval, it's 2s by default
+func WithWatchInterval(d time.Duration) WatchOpt {
+	return func(w *configWatcher) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithWatchBuilder specifies how to build a new logger from a changed
+// config, such as a BuilderFunc of hedzr/logex. The new logger replaces
+// the package-level one, which is changed in place by default.
+func WithWatchBuilder(builder BuilderFunc) WatchOpt {
+	return func(w *configWatcher) {
+		if builder != nil {
+			w.builder = builder
+		}
+	}
+}
+
+// WithWatchConfigOpts passes opts to LoadConfig
+func WithWatchConfigOpts(opts ...Opt) WatchOpt {
+	return func(w *configWatcher) {
+		w.lcOpts = append(w.lcOpts, opts...)
+	}
+}
+
+type configWatcher struct {
+	path     string
+	interval time.Duration
+	builder  BuilderFunc
+	lcOpts   []Opt
+	current  *LoggerConfig
+	stamp    string
+	done     chan struct{}
+	once     sync.Once
+	wg       sync.WaitGroup
+}
+
+func (w *configWatcher) stop() {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+}
+
+func (w *configWatcher) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *configWatcher) poll() {
+	stamp, err := statStamp(w.path)
+	if err != nil || stamp == w.stamp {
+		return // the file might be being replaced, try it next time
+	}
+	w.stamp = stamp
+
+	lc, err := parseConfig(w.path, w.lcOpts...)
+	if err != nil {
+		Warnf("logging config not reloaded: %v", err)
+		return
+	}
+	diffs := diffConfig(w.current, lc)
+	if len(diffs) == 0 {
+		return
+	}
+	if err = w.apply(lc, diffs); err != nil {
+		Warnf("logging config not reloaded from %s: %s: %v", w.path, diffs, err)
+		return
+	}
+	w.current = lc
+	Printf("logging config reloaded from %s: %s", w.path, diffs) // printed regardless of the new level
+}
+
+// apply applies the changed config to the package-level logger, see
+// WatchConfig
+func (w *configWatcher) apply(lc *LoggerConfig, diffs configDiffs) error {
+	lvl, _ := ParseLevel(lc.Level)
+	l := GetLogger()
+	switch cl, ok := l.(CL); {
+	case diffs.levelOnly():
+		l.SetLevel(lvl)
+	case w.builder != nil:
+		// the new logger is fully configured before it's put in, so
+		// the callers never see the new logger with the old level
+		nl := w.builder(lc)
+		nl.SetLevel(lvl)
+		setLogger(nl)
+		log.SetOutput(nl.GetOutput())
+	case ok:
+		return cl.ApplyConfig(lc, diffs.keys())
+	default:
+		return fmt.Errorf("%s cannot be applied to %T", strings.Join(diffs.keys(), ", "), l)
+	}
+	return nil
+}
+
+// statStamp identifies a version of the file by its size and mtime
+func statStamp(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", fi.Size(), fi.ModTime().UnixNano()), nil
+}
+
+// configDiffs are the changed fields of LoggerConfig
+type configDiffs []configDiff
+
+type configDiff struct {
+	key      string // the json/yaml key
+	from, to interface{}
+}
+
+func (d configDiffs) String() string {
+	var ss []string
+	for _, c := range d {
+		ss = append(ss, fmt.Sprintf("%s: %v -> %v", c.key, c.from, c.to))
+	}
+	return strings.Join(ss, ", ")
+}
+
+// keys returns the json/yaml keys of the changed fields
+func (d configDiffs) keys() (keys []string) {
+	for _, c := range d {
+		keys = append(keys, c.key)
+	}
+	return
+}
+
+// levelOnly tests if the diffs can be applied by SetLevel
+func (d configDiffs) levelOnly() bool {
+	for _, c := range d {
+		if c.key != "level" && c.key != "debug" && c.key != "trace" {
+			return false
+		}
+	}
+	return true
+}
+
+func diffConfig(a, b *LoggerConfig) (diffs configDiffs) {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	for i := 0; i < va.NumField(); i++ {
+		fa, fb := va.Field(i).Interface(), vb.Field(i).Interface()
+		if !reflect.DeepEqual(fa, fb) {
+			diffs = append(diffs, configDiff{configKey(va.Type().Field(i)), fa, fb})
+		}
+	}
+	return
+}
+
+func configKey(f reflect.StructField) string {
+	if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+		return tag
+	}
+	return strings.ToLower(f.Name)
+}
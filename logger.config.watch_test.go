@@ -0,0 +1,196 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchConfig(t *testing.T) {
+	requireOutput(t)
+	path, cleanup := writeTempConfig(t, "log.yaml", "level: info\nformat: text\n")
+	defer cleanup()
+
+	saved := GetLogger()
+	savedLevel := saved.GetLevel()
+	defer func() {
+		SetLogger(saved)
+		SetLevel(savedLevel)
+	}()
+	SetLevel(InfoLevel)
+
+	var built []*LoggerConfig
+	w, err := newConfigWatcher(path, WithWatchBuilder(func(lc *LoggerConfig) Logger {
+		built = append(built, lc)
+		return NewStdLoggerWithConfig(lc)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := func(content string, n int) string {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		future := time.Now().Add(time.Duration(n) * time.Second)
+		_ = os.Chtimes(path, future, future)
+		return captureStdOutput(w.poll)
+	}
+
+	if out := captureStdOutput(w.poll); out != "" {
+		t.Fatalf("an unchanged file should not be reloaded: %q", out)
+	}
+
+	out := update("level: warn\nformat: text\n", 1)
+	if GetLevel() != WarnLevel || len(built) != 0 || !strings.Contains(out, "level: info -> warn") {
+		t.Fatalf("a level change should be applied without rebuilding: %q", out)
+	}
+
+	out = update("level: debug\nformat: xml\n", 2)
+	if GetLevel() != WarnLevel || len(built) != 0 || !strings.Contains(out, `not reloaded`) {
+		t.Fatalf("an invalid config should be rejected as a whole: %q", out)
+	}
+
+	out = update("level: debug\nformat: json\n", 3)
+	if len(built) != 1 || built[0].Format != "json" || GetLevel() != DebugLevel || GetLogger() == saved {
+		t.Fatalf("a new logger should be built with the new config: %q", out)
+	}
+	if !strings.Contains(out, `"msg":"logging config reloaded`) || !strings.Contains(out, "format: text -\\u003e json") {
+		t.Fatalf("the diff should be logged by the new logger: %q", out)
+	}
+}
+
+func TestWatchConfig_inPlace(t *testing.T) {
+	requireOutput(t)
+	path, cleanup := writeTempConfig(t, "log.yaml", "level: info\nformat: text\n")
+	defer cleanup()
+
+	saved := GetLogger()
+	defer SetLogger(saved)
+	dl := NewDedupLogger(NewStdLoggerWithConfig(NewLoggerConfig()), time.Hour)
+	SetLogger(dl)
+
+	w, err := newConfigWatcher(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := func(content string, n int) string {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		future := time.Now().Add(time.Duration(n) * time.Second)
+		_ = os.Chtimes(path, future, future)
+		return captureStdOutput(w.poll)
+	}
+
+	out := update("level: debug\nformat: json\n", 1)
+	if GetLogger() != dl || GetLevel() != DebugLevel || !strings.Contains(out, `"msg":"logging config reloaded`) {
+		t.Fatalf("the installed logger should be changed in place: %q", out)
+	}
+
+	out = update("level: warn\nformat: json\ntarget: file\n", 2)
+	if GetLogger() != dl || GetLevel() != DebugLevel || !strings.Contains(out, "not reloaded") || !strings.Contains(out, "target not supported") {
+		t.Fatalf("a change which cannot be applied should be refused as a whole: %q", out)
+	}
+
+	SetLogger(FromSystemdLogger(&recordSL{}, WithSystemdMirror(newStdLogger())))
+	out = update("level: warn\nformat: text\ntarget: file\n", 3)
+	if !strings.Contains(out, "target, debug cannot be applied to *log.toSystemdLogger") {
+		t.Fatalf("a logger without CL should be kept as is: %q", out)
+	}
+}
+
+func TestWatchConfig_stop(t *testing.T) {
+	path, cleanup := writeTempConfig(t, "log.yaml", "level: info\n")
+	defer cleanup()
+
+	if _, err := WatchConfig(path + ".missing"); err == nil {
+		t.Fatal("expect an error for a missing file")
+	}
+	stop, err := WatchConfig(path, WithWatchInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	stop()
+	stop() // stop can be called more than once
+}
+
+func TestWatchConfig_concurrent(t *testing.T) {
+	path, cleanup := writeTempConfig(t, "log.yaml", "level: info\nformat: text\n")
+	defer cleanup()
+
+	saved := GetLogger()
+	savedLevel := saved.GetLevel()
+	defer func() {
+		SetLogger(saved)
+		SetLevel(savedLevel)
+	}()
+	SetLogger(newStdLogger()) // changed in place
+
+	stop, err := WatchConfig(path, WithWatchInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					Debugf("debug")
+					Infof("info")
+					_ = GetLevel()
+				}
+			}
+		}()
+	}
+
+	// the text entries are written by stdlib log with its own lock, and
+	// the json ones by outputLock, so the writer is locked for the mix
+	log.SetOutput(&lockedWriter{})
+	defer log.SetOutput(os.Stderr)
+	func() {
+		for i, content := range []string{
+			"level: warn\nformat: text\n",
+			"level: debug\nformat: json\n",
+			"level: error\nformat: json\n",
+		} {
+			if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			future := time.Now().Add(time.Duration(i+1) * time.Second)
+			_ = os.Chtimes(path, future, future)
+			time.Sleep(20 * time.Millisecond)
+		}
+		close(done)
+		wg.Wait()
+		stop()
+	}()
+	if GetLevel() != ErrorLevel {
+		t.Fatalf("the last config should be applied, got %v", GetLevel())
+	}
+}
+
+type lockedWriter struct {
+	sync.Mutex
+	bytes.Buffer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	return w.Buffer.Write(p)
+}
@@ -0,0 +1,307 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewDedupLogger wraps l with a logger which collapses the repeated
+// entries. An entry is suppressed if it has the same level, message
+// and fields with the last one and it comes within window since the
+// last one was printed. The count of suppressed entries is reported
+// as "last message repeated N times" when the window ends or a
+// different entry comes.
+//
+// The Fatal and Panic entries are never suppressed. The returned
+// logger can be put into the package-level by SetLogger, and it can
+// wrap any Logger such as the one from FromSystemdLogger:
+//
+//	dl := log.NewDedupLogger(log.FromSystemdLogger(sl), 5*time.Second)
+//	closers.RegisterPeripheral(dl.(basics.Peripheral))
+//	log.SetLogger(dl)
+func NewDedupLogger(l Logger, window time.Duration) Logger {
+	return &dedupLogger{Logger: l, st: &dedupState{window: window}}
+}
+
+type dedupLogger struct {
+	Logger
+	fields map[string]interface{}
+	err    error
+	st     *dedupState
+}
+
+// dedupState is shared by a dedupLogger and its children
+type dedupState struct {
+	sync.Mutex
+	window   time.Duration
+	last     string // the key of the last printed entry
+	lastAt   time.Time
+	lastLvl  Level
+	lastL    *dedupLogger
+	repeated int
+	timer    *time.Timer
+}
+
+func (d *dedupLogger) child(l Logger) *dedupLogger {
+	c := &dedupLogger{Logger: l, err: d.err, st: d.st, fields: make(map[string]interface{}, len(d.fields))}
+	for k, v := range d.fields {
+		c.fields[k] = v
+	}
+	return c
+}
+
+func (d *dedupLogger) With(key string, val interface{}) Logger {
+	c := d.child(d.Logger.With(key, val))
+	c.fields[key] = val
+	return c
+}
+
+func (d *dedupLogger) WithFields(fields map[string]interface{}) Logger {
+	c := d.child(d.Logger.WithFields(fields))
+	for k, v := range fields {
+		c.fields[k] = v
+	}
+	return c
+}
+
+func (d *dedupLogger) WithError(err error) Logger {
+	var l Logger
+	if el, ok := d.Logger.(EL); ok {
+		l = el.WithError(err)
+	} else {
+		l = d.Logger.With("error", err)
+	}
+	c := d.child(l)
+	c.err = err
+	return c
+}
+
+func (d *dedupLogger) AddSkip(skip int) Logger {
+	return d.child(d.Logger.AddSkip(skip))
+}
+
+// ApplyConfig implements CL interface if the wrapped logger does
+func (d *dedupLogger) ApplyConfig(config *LoggerConfig, keys []string) error {
+	if cl, ok := d.Logger.(CL); ok {
+		return cl.ApplyConfig(config, keys)
+	}
+	return fmt.Errorf("%s cannot be applied to %T", strings.Join(keys, ", "), d.Logger)
+}
+
+// Close stops the pending timer and reports the suppressed entries
+func (d *dedupLogger) Close() {
+	d.st.Lock()
+	sum := d.st.flush()
+	d.st.Unlock()
+	sum.write()
+}
+
+func (d *dedupLogger) key(lvl Level, msg string) string {
+	var sb strings.Builder
+	sb.WriteString(lvl.String())
+	sb.WriteByte('|')
+	sb.WriteString(msg)
+	keys := make([]string, 0, len(d.fields))
+	for k := range d.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(&sb, "|%s=%v", k, d.fields[k])
+	}
+	if d.err != nil {
+		sb.WriteString("|error=")
+		sb.WriteString(d.err.Error())
+	}
+	return sb.String()
+}
+
+// allow tests whether an entry should be printed. The summary of the
+// suppressed entries is returned too, which must be written before a
+// different entry, see summary.write.
+func (d *dedupLogger) allow(lvl Level, msg string, gated bool) (*summary, bool) {
+	if gated && !d.Logger.GetLevel().Allows(lvl) {
+		return nil, false
+	}
+
+	k, now := d.key(lvl, msg), time.Now()
+	st := d.st
+	st.Lock()
+	defer st.Unlock()
+	if k == st.last && now.Sub(st.lastAt) < st.window {
+		st.repeated++
+		if st.timer == nil {
+			st.timer = time.AfterFunc(st.window-now.Sub(st.lastAt), func() {
+				st.Lock()
+				sum := st.flush()
+				st.Unlock()
+				sum.write()
+			})
+		}
+		return nil, false
+	}
+
+	sum := st.flush()
+	st.last, st.lastAt, st.lastLvl, st.lastL = k, now, lvl, d
+	return sum, true
+}
+
+// summary reports the count of the suppressed entries
+type summary struct {
+	l   *dedupLogger
+	lvl Level
+	msg string
+}
+
+// write writes the summary, it's a no-op for a nil summary. It must
+// be called without the lock held, so that a sink logging through
+// the same dedupLogger doesn't deadlock.
+func (sum *summary) write() {
+	if sum != nil {
+		_ = sum.l.write(sum.lvl, sum.msg)
+	}
+}
+
+// flush stops the pending timer, resets the state so that the next
+// entry will be printed, and returns the summary of the suppressed
+// entries or nil. The lock must be held by caller.
+func (st *dedupState) flush() (sum *summary) {
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+	if st.repeated > 0 {
+		sum = &summary{l: st.lastL, lvl: st.lastLvl, msg: fmt.Sprintf("last message repeated %d times", st.repeated)}
+	}
+	st.last, st.repeated = "", 0
+	return
+}
+
+// write writes msg at lvl through the richest interface implemented
+// by d.Logger, so that NoticeLevel, CriticalLevel and the custom
+// levels are kept. It never exits or panics.
+//
+// A summary written by the timer has no caller, since no frame out
+// of the known packages is on the stack of the timer goroutine.
+func (d *dedupLogger) write(lvl Level, msg string) error {
+	if ol, ok := d.Logger.(OL); ok {
+		return ol.Output(lvl, msg)
+	}
+	if ll, ok := d.Logger.(LL); ok {
+		ll.Logf(lvl, "%s", msg)
+		return nil
+	}
+	switch lvl.builtin() {
+	case TraceLevel:
+		d.Logger.Tracef("%s", msg)
+	case DebugLevel:
+		d.Logger.Debugf("%s", msg)
+	case InfoLevel:
+		d.Logger.Infof("%s", msg)
+	case WarnLevel:
+		d.Logger.Warnf("%s", msg)
+	default:
+		d.Logger.Errorf("%s", msg)
+	}
+	return nil
+}
+
+func (d *dedupLogger) out(lvl Level, msg string) {
+	_ = d.output(lvl, msg)
+}
+
+// output is out with the error of d.Logger returned
+func (d *dedupLogger) output(lvl Level, msg string) error {
+	sum, ok := d.allow(lvl, msg, true)
+	sum.write()
+	if ok {
+		return d.write(lvl, msg)
+	}
+	return nil
+}
+
+// print is for Print, Printf and Println which ignore the level
+func (d *dedupLogger) print(msg string) {
+	sum, ok := d.allow(InfoLevel, msg, false)
+	sum.write()
+	if ok {
+		d.Logger.Printf("%s", msg)
+	}
+}
+
+// Logf implements LL interface. The entries at FatalLevel and
+// PanicLevel are never suppressed, but they never exit or panic.
+func (d *dedupLogger) Logf(lvl Level, msg string, args ...interface{}) {
+	_ = d.Output(lvl, fmt.Sprintf(msg, args...))
+}
+
+// Output implements OL interface, it is Logf with the error of the
+// underlying logger returned.
+func (d *dedupLogger) Output(lvl Level, msg string) error {
+	switch sev := lvl.Severity(); {
+	case sev < 0:
+		return nil // OffLevel or an unknown level
+	case sev <= FatalLevel.Severity():
+		d.Close()
+		return d.write(lvl, msg)
+	}
+	return d.output(lvl, msg)
+}
+
+func (d *dedupLogger) Trace(args ...interface{}) { d.out(TraceLevel, fmt.Sprint(args...)) }
+func (d *dedupLogger) Debug(args ...interface{}) { d.out(DebugLevel, fmt.Sprint(args...)) }
+func (d *dedupLogger) Info(args ...interface{})  { d.out(InfoLevel, fmt.Sprint(args...)) }
+func (d *dedupLogger) Warn(args ...interface{})  { d.out(WarnLevel, fmt.Sprint(args...)) }
+func (d *dedupLogger) Error(args ...interface{}) { d.out(ErrorLevel, fmt.Sprint(args...)) }
+
+func (d *dedupLogger) Fatal(args ...interface{}) {
+	d.Close()
+	d.Logger.Fatalf("%s", fmt.Sprint(args...))
+}
+
+func (d *dedupLogger) Panic(args ...interface{}) {
+	d.Close()
+	d.Logger.Panicf("%s", fmt.Sprint(args...))
+}
+
+func (d *dedupLogger) Print(args ...interface{})   { d.print(fmt.Sprint(args...)) }
+func (d *dedupLogger) Println(args ...interface{}) { d.print(fmt.Sprintln(args...)) }
+
+func (d *dedupLogger) Tracef(msg string, args ...interface{}) {
+	d.out(TraceLevel, fmt.Sprintf(msg, args...))
+}
+
+func (d *dedupLogger) Debugf(msg string, args ...interface{}) {
+	d.out(DebugLevel, fmt.Sprintf(msg, args...))
+}
+
+func (d *dedupLogger) Infof(msg string, args ...interface{}) {
+	d.out(InfoLevel, fmt.Sprintf(msg, args...))
+}
+
+func (d *dedupLogger) Warnf(msg string, args ...interface{}) {
+	d.out(WarnLevel, fmt.Sprintf(msg, args...))
+}
+
+func (d *dedupLogger) Errorf(msg string, args ...interface{}) {
+	d.out(ErrorLevel, fmt.Sprintf(msg, args...))
+}
+
+func (d *dedupLogger) Fatalf(msg string, args ...interface{}) {
+	d.Close()
+	d.Logger.Fatalf(msg, args...)
+}
+
+func (d *dedupLogger) Panicf(msg string, args ...interface{}) {
+	d.Close()
+	d.Logger.Panicf(msg, args...)
+}
+
+func (d *dedupLogger) Printf(msg string, args ...interface{}) {
+	d.print(fmt.Sprintf(msg, args...))
+}
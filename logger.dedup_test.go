@@ -0,0 +1,152 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedupLogger(t *testing.T) {
+	requireOutput(t)
+	dl := NewDedupLogger(newStdLogger(), time.Hour)
+	out := captureStdOutput(func() {
+		for i := 0; i < 5; i++ {
+			dl.Errorf("downstream failed: %v", "EOF")
+		}
+		dl.With("k", 1).Errorf("downstream failed: %v", "EOF") // different fields
+		dl.Warnf("different")
+		dl.Warnf("different")
+		dl.(interface{ Close() }).Close()
+	})
+	t.Log(out)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	expects := []string{
+		"downstream failed: EOF",
+		"last message repeated 4 times",
+		"downstream failed: EOF k=1",
+		"different",
+		"last message repeated 1 times",
+	}
+	if len(lines) != len(expects) {
+		t.Fatalf("expect %d lines but got %d", len(expects), len(lines))
+	}
+	for i, s := range expects {
+		if !strings.HasSuffix(lines[i], s) {
+			t.Fatalf("line %d: expect %q in %q", i, s, lines[i])
+		}
+	}
+	if !strings.Contains(lines[0], "logger.dedup_test.go:") {
+		t.Fatalf("bad caller: %q", lines[0])
+	}
+}
+
+func TestDedupLogger_window(t *testing.T) {
+	requireOutput(t)
+	dl := NewDedupLogger(newStdLogger(), 20*time.Millisecond)
+	out := captureStdOutput(func() {
+		AsL(dl).Info("tick")
+		AsL(dl).Info("tick")
+		AsL(dl).Debug("filtered by level")
+		time.Sleep(60 * time.Millisecond)
+		AsL(dl).Info("tick")
+	})
+	t.Log(out)
+
+	if c := strings.Count(out, "tick"); c != 2 || !strings.Contains(out, "last message repeated 1 times") {
+		t.Fatalf("bad output: %q", out)
+	}
+}
+
+func TestDedupLogger_packageLevel(t *testing.T) {
+	requireOutput(t)
+	old := GetLogger()
+	SetLogger(NewDedupLogger(old, time.Hour))
+	defer SetLogger(old)
+
+	out := captureStdOutput(func() {
+		Infof("same")
+		Infof("same")
+		Info("other")
+	})
+	if strings.Count(out, "same") != 1 || !strings.Contains(out, "last message repeated 1 times") {
+		t.Fatalf("bad output: %q", out)
+	}
+}
+
+func TestDedupLogger_levels(t *testing.T) {
+	requireOutput(t)
+	dl := NewDedupLogger(newStdLoggerWith(InfoLevel), time.Hour)
+	out := captureStdOutput(func() {
+		for i := 0; i < 3; i++ {
+			dl.(LL).Logf(NoticeLevel, "notice %d", 1)
+		}
+		if err := dl.(OL).Output(DebugLevel, "hidden"); err != nil {
+			t.Fatal(err)
+		}
+		_ = dl.(OL).Output(CriticalLevel, "critical")
+	})
+	t.Log(out)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	expects := []string{"notice 1", "last message repeated 2 times", "critical"}
+	if len(lines) != len(expects) {
+		t.Fatalf("expect %d lines but got %d", len(expects), len(lines))
+	}
+	for i, s := range expects {
+		if !strings.HasSuffix(lines[i], s) {
+			t.Fatalf("line %d: expect %q in %q", i, s, lines[i])
+		}
+	}
+}
+
+func TestDedupLogger_gating(t *testing.T) {
+	requireOutput(t)
+	dl := NewDedupLogger(newStdLoggerWith(ErrorLevel), time.Hour)
+	out := captureStdOutput(func() {
+		dl.Warnf("warn")
+		dl.(LL).Logf(NoticeLevel, "notice")
+		dl.(LL).Logf(CriticalLevel, "critical")
+		dl.Errorf("error")
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "critical") || !strings.HasSuffix(lines[1], "error") {
+		t.Fatalf("expect critical and error only, got %q", out)
+	}
+}
+
+// reentrantL logs through the dedupLogger while writing the summary,
+// like a sink reporting its own errors by the package logger
+type reentrantL struct {
+	Logger
+	dl     Logger
+	warned chan string
+}
+
+func (r *reentrantL) Infof(msg string, args ...interface{}) {
+	if strings.HasPrefix(fmt.Sprintf(msg, args...), "last message repeated") {
+		r.dl.Warnf("sink is slow")
+	}
+}
+
+func (r *reentrantL) Warnf(msg string, args ...interface{}) {
+	r.warned <- fmt.Sprintf(msg, args...)
+}
+
+func TestDedupLogger_timerUnlocked(t *testing.T) {
+	r := &reentrantL{Logger: newStdLoggerWith(InfoLevel), warned: make(chan string, 1)}
+	dl := NewDedupLogger(r, 10*time.Millisecond)
+	r.dl = dl
+
+	dl.Infof("tick")
+	dl.Infof("tick") // the summary is written by the timer
+	select {
+	case msg := <-r.warned:
+		if msg != "sink is slow" {
+			t.Fatalf("bad entry %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlocked while writing the summary")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"gopkg.in/hedzr/errors.v3"
+)
+
+// WithError returns a child logger of the package-level logger which
+// carries err. The error will be rendered with its whole chain, the
+// attached errors (see errors.WithErrors) and its stack trace if it
+// has one. For example:
+//
+//	log.WithError(err).Errorf("cannot open %q", filename)
+//
+// If the package-level logger doesn't implement EL interface, err
+// will be put into a field named "error".
+func WithError(err error) Logger {
+	return withError(GetLogger(), err)
+}
+
+func withError(l Logger, err error) Logger {
+	if el, ok := l.(EL); ok {
+		return el.WithError(err)
+	}
+	return l.With("error", err)
+}
+
+// errorInfo is the rendered form of an error chain
+type errorInfo struct {
+	Message string       `json:"message"`
+	Causes  []*errorInfo `json:"causes,omitempty"`
+	Stack   []string     `json:"-"`
+}
+
+// maxErrorDepth limits the walking on a (maybe cyclic) errors tree
+const maxErrorDepth = 16
+
+// renderError walks err and its inner errors.
+//
+// The inner errors are collected in this order: the attached errors
+// of a hedzr/errors container (by Causes()), the joined errors (by
+// Unwrap() []error), or the wrapped error (by Unwrap() error).
+//
+// We never call Unwrap() on a hedzr/errors container because its
+// Unwrap() is stateful.
+func renderError(err error) *errorInfo {
+	ei := walkError(err, 0)
+	if st := deepestStack(err, 0); len(st) > 0 {
+		ei.Stack = st
+	}
+	return ei
+}
+
+func walkError(err error, depth int) *errorInfo {
+	ei := &errorInfo{Message: err.Error()}
+	if depth >= maxErrorDepth {
+		return ei
+	}
+	for _, e := range innerErrors(err) {
+		ei.Causes = append(ei.Causes, walkError(e, depth+1))
+	}
+	return ei
+}
+
+func innerErrors(err error) (errs []error) {
+	var src []error
+	switch x := err.(type) {
+	case interface{ Causes() []error }:
+		src = x.Causes()
+	case interface{ Unwrap() []error }:
+		src = x.Unwrap()
+	case interface{ Unwrap() error }:
+		src = []error{x.Unwrap()}
+	}
+	for _, e := range src {
+		if e != nil && e != err {
+			errs = append(errs, e)
+		}
+	}
+	return
+}
+
+// deepestStack returns the stack trace nearest to the origin of err
+func deepestStack(err error, depth int) (stack []string) {
+	if depth < maxErrorDepth {
+		for _, e := range innerErrors(err) {
+			if st := deepestStack(e, depth+1); len(st) > 0 {
+				return st
+			}
+		}
+	}
+	return stackOf(err)
+}
+
+func stackOf(err error) (stack []string) {
+	if cs, ok := err.(interface{ capturedStack() []string }); ok {
+		return cs.capturedStack()
+	}
+	if w, ok := err.(*errors.WithStackInfo); ok && w.Stack == nil {
+		return
+	}
+	if st, ok := err.(interface{ StackTrace() errors.StackTrace }); ok {
+		for _, f := range st.StackTrace() {
+			stack = append(stack, frameString(uintptr(f)-1))
+		}
+	}
+	return
+}
+
+// frameString renders a program counter as "function file:line"
+func frameString(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	file, line := fn.FileLine(pc)
+	return fmt.Sprintf("%s %s:%d", fn.Name(), file, line)
+}
+
+func (ei *errorInfo) writeCauses(sb *strings.Builder, indent string) {
+	for _, c := range ei.Causes {
+		sb.WriteByte('\n')
+		sb.WriteString(indent)
+		if len(ei.Causes) > 1 {
+			sb.WriteString("- ")
+		} else {
+			sb.WriteString("caused by: ")
+		}
+		sb.WriteString(c.Message)
+		c.writeCauses(sb, indent+"  ")
+	}
+}
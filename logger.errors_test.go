@@ -0,0 +1,76 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/hedzr/errors.v3"
+)
+
+// requireOutput skips a test which checks the output, since nothing
+// is printed in a veryquiet build
+func requireOutput(t *testing.T) {
+	if VeryQuietEnabled {
+		t.Skip("nothing is printed in a veryquiet build")
+	}
+}
+
+func captureStdOutput(fn func()) string {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	fn()
+	return buf.String()
+}
+
+func TestWithError_text(t *testing.T) {
+	requireOutput(t)
+	err := errors.New("open config").WithErrors(io.EOF, io.ErrClosedPipe)
+	out := captureStdOutput(func() {
+		ErrorfWith(errors.Wrap(err, "loading"), "cannot start %v", "app")
+	})
+	t.Log(out)
+
+	for _, s := range []string{"cannot start app", `error="loading [open config`, "caused by: open config", "- EOF", "- io: read/write on closed pipe", "stack:"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expect %q in output", s)
+		}
+	}
+}
+
+func TestWithError_json(t *testing.T) {
+	requireOutput(t)
+	l := NewStdLoggerWithConfig(NewLoggerConfig(func(lc *LoggerConfig) { lc.Format = "json" }))
+	out := captureStdOutput(func() {
+		l.(EL).WithError(errors.New("bad").WithErrors(io.EOF)).With("k", 1).Errorf("failed")
+	})
+	t.Log(out)
+
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["msg"] != "failed" || m["level"] != "error" || m["k"] != float64(1) {
+		t.Fatalf("bad entry: %v", m)
+	}
+	e, ok := m["error"].(map[string]interface{})
+	if !ok || e["message"] == nil || len(e["causes"].([]interface{})) != 1 {
+		t.Fatalf("bad error field: %v", m["error"])
+	}
+	if st, ok := m["stack"].([]interface{}); !ok || len(st) == 0 {
+		t.Fatalf("bad stack field: %v", m["stack"])
+	}
+}
+
+func TestWithError_childIsolated(t *testing.T) {
+	l := newStdLogger()
+	_ = l.(EL).WithError(io.EOF).With("k", "v")
+	if sl := l.(*stdLogger); sl.err != nil || len(sl.fields) != 0 {
+		t.Fatalf("parent logger was modified: %v, %v", sl.err, sl.fields)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/hedzr/log/buildtags"
+)
+
+// SetExitFunc replaces os.Exit which is called by the Fatal path of
+// the built-in loggers. Passing nil restores os.Exit.
+//
+// If fn returns, Fatal returns to its caller too. So a test can
+// intercept the exiting deterministically:
+//
+//	var code int
+//	log.SetExitFunc(func(c int) { code = c })
+//	defer log.SetExitFunc(nil)
+func SetExitFunc(fn func(code int)) {
+	exitLock.Lock()
+	defer exitLock.Unlock()
+	exitFunc = fn
+}
+
+// SetExitCode sets the exit code used by the Fatal path, it is 1 by
+// default.
+func SetExitCode(code int) {
+	exitLock.Lock()
+	defer exitLock.Unlock()
+	exitCode = code
+}
+
+// RegisterExitHooks adds the hooks which will be run in order before
+// the Fatal path exits, for example:
+//
+//	log.RegisterExitHooks(closers.Close)
+//
+// Before the hooks, the output device of the package-level logger
+// will be flushed if it has a Sync() or Flush() method.
+func RegisterExitHooks(hooks ...func()) {
+	exitLock.Lock()
+	defer exitLock.Unlock()
+	for _, h := range hooks {
+		if h != nil {
+			exitHooks = append(exitHooks, h)
+		}
+	}
+}
+
+// Exit runs the exit hooks and exits with code through the exit
+// function, see SetExitFunc.
+func Exit(code int) {
+	exitLock.Lock()
+	fn, hooks := exitFunc, exitHooks
+	exitLock.Unlock()
+
+	runExitHooks(hooks)
+	if fn == nil {
+		fn = os.Exit
+	}
+	fn(code)
+}
+
+var (
+	exitLock  sync.Mutex
+	exitFunc  func(code int)
+	exitCode  = 1
+	exitHooks []func()
+)
+
+// exitOnFatal is the Fatal path of the built-in loggers.
+//
+// Under go test, it panics with msg instead of exiting as before,
+// unless an exit function has been set by SetExitFunc.
+func exitOnFatal(msg string) {
+	exitLock.Lock()
+	fn, code := exitFunc, exitCode
+	exitLock.Unlock()
+
+	if fn == nil && InTesting() {
+		panic(msg)
+	}
+	Exit(code)
+}
+
+// hasExitFunc tests if an exit function has been set by SetExitFunc
+func hasExitFunc() bool {
+	exitLock.Lock()
+	defer exitLock.Unlock()
+	return exitFunc != nil
+}
+
+func runExitHooks(hooks []func()) {
+	flushOutput()
+	for _, h := range hooks {
+		runExitHook(h)
+	}
+}
+
+func runExitHook(h func()) {
+	defer func() {
+		if r := recover(); r != nil && !buildtags.VeryQuietEnabled {
+			_, _ = fmt.Fprintf(os.Stderr, "exit hook panicked: %v\n", r)
+		}
+	}()
+	h()
+}
+
+func flushOutput() {
+	switch w := GetLogger().GetOutput().(type) {
+	case interface{ Sync() error }:
+		_ = w.Sync()
+	case interface{ Flush() error }:
+		_ = w.Flush()
+	}
+}
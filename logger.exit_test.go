@@ -0,0 +1,49 @@
+package log
+
+import (
+	"testing"
+)
+
+func TestSetExitFunc(t *testing.T) {
+	var code int
+	var hooks []string
+	SetExitFunc(func(c int) { code = c })
+	SetExitCode(3)
+	RegisterExitHooks(func() { hooks = append(hooks, "flush") }, nil, func() {
+		hooks = append(hooks, "panicked")
+		panic("bad hook")
+	}, func() { hooks = append(hooks, "close") })
+	defer func() {
+		SetExitFunc(nil)
+		SetExitCode(1)
+		exitHooks = nil
+	}()
+
+	_ = captureStdOutput(func() {
+		Fatalf("fatal %v", 1)
+	})
+	if code != 3 {
+		t.Fatalf("expect exit code 3, but got %d", code)
+	}
+	if len(hooks) != 3 || hooks[0] != "flush" || hooks[2] != "close" {
+		t.Fatalf("bad hooks running: %v", hooks)
+	}
+
+	code, hooks = 0, nil
+	_ = captureStdOutput(func() {
+		NewDummyLogger().Fatalf("fatal")
+		AsL(FromSystemdLogger(AsSystemdLogger(AsL(NewDummyLogger())))).Fatal("fatal")
+	})
+	if code != 3 || len(hooks) != 6 {
+		t.Fatalf("expect all loggers exit through exit func, got code %d, hooks %v", code, hooks)
+	}
+}
+
+func TestExitOnFatal_testing(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "fatal" {
+			t.Fatalf("expect panic under testing without exit func, but got %v", r)
+		}
+	}()
+	exitOnFatal("fatal")
+}
@@ -3,140 +3,270 @@ package log
 import (
 	"fmt"
 	"io"
-	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hedzr/log/buildtags"
 )
 
 // FromSystemdLogger converts a SystemdLogger to Logger so that you can put it into `log` system via log.SetLogger.
-func FromSystemdLogger(sl SystemdLogger) Logger {
+//
+// The richer interfaces of sl are detected at runtime: a
+// SystemdFieldsLogger receives the fields of With, WithFields and
+// WithError as the journald-style fields; a SystemdLoggerEx receives
+// each level at its own priority, such as Debug for DebugLevel.
+// Otherwise, the levels are mapped to Error, Warning and Info, and the
+// fields are appended to the message as "KEY=value".
+//
+// The entries are written to sl only by default. Use WithSystemdMirror
+// to write them to another logger too, or WithSystemdFallback to write
+// them to another logger while sl fails:
+//
+//	log.SetLogger(log.FromSystemdLogger(j, log.WithSystemdFallback(nil)))
+//
+// The level is shared by the returned logger and its children from
+// With, WithFields and WithError, so SetLevel (and log.SetLevel after
+// log.SetLogger) takes effect on all of them. Like the std logger,
+// Print and Println ignore the level.
+func FromSystemdLogger(sl SystemdLogger, opts ...SystemdOpt) Logger {
 	l := &toSystemdLogger{
-		lvl: logger.GetLevel(),
-		w:   nil,
-		sl:  sl,
-		old: logger,
+		w:  nil,
+		sl: sl,
+		st: &systemdState{lvl: int32(GetLogger().GetLevel())},
+	}
+	for _, opt := range opts {
+		opt(l.st)
 	}
 	return l
 }
 
+// SystemdOpt is a functional option for FromSystemdLogger
+type SystemdOpt func(st *systemdState)
+
+// WithSystemdMirror writes every entry to l as well as the system log.
+// If l is nil, the package-level logger at the time of
+// FromSystemdLogger is used.
+func WithSystemdMirror(l Logger) SystemdOpt {
+	return func(st *systemdState) {
+		st.old, st.mode = orPackageLogger(l), systemdMirror
+	}
+}
+
+// WithSystemdFallback writes an entry to l only if the system log
+// returns an error for it, such as the daemon is gone. If l is nil,
+// the package-level logger at the time of FromSystemdLogger is used.
+func WithSystemdFallback(l Logger) SystemdOpt {
+	return func(st *systemdState) {
+		st.old, st.mode = orPackageLogger(l), systemdFallback
+	}
+}
+
+func orPackageLogger(l Logger) Logger {
+	if l == nil {
+		return GetLogger()
+	}
+	return l
+}
+
+const (
+	systemdNoMirror = iota
+	systemdMirror
+	systemdFallback
+)
+
 type toSystemdLogger struct {
-	lvl Level
-	w   io.Writer
-	sl  SystemdLogger
-	old Logger
+	w      io.Writer
+	sl     SystemdLogger
+	st     *systemdState
+	fields map[string]interface{}
+	err    error
+}
+
+// systemdState is shared by a toSystemdLogger and its children
+type systemdState struct {
+	lvl  int32 // the Level, accessed atomically
+	old  Logger
+	mode int
+}
+
+func (d *toSystemdLogger) clone() *toSystemdLogger {
+	c := *d
+	c.fields = make(map[string]interface{}, len(d.fields))
+	for k, v := range d.fields {
+		c.fields[k] = v
+	}
+	return &c
 }
 
 func (d *toSystemdLogger) With(key string, val interface{}) Logger {
-	return d
+	c := d.clone()
+	c.fields[key] = val
+	return c
 }
 
 func (d *toSystemdLogger) WithFields(fields map[string]interface{}) Logger {
-	return d
+	c := d.clone()
+	for k, v := range fields {
+		c.fields[k] = v
+	}
+	return c
 }
 
-func (d *toSystemdLogger) Trace(args ...interface{}) {
-	if d.lvl >= TraceLevel {
-		_ = d.sl.Info(args...)
-		if d.old != nil {
-			AsL(d.old).Trace(args...)
+func (d *toSystemdLogger) WithError(err error) Logger {
+	c := d.clone()
+	c.err = err
+	return c
+}
+
+// send writes msg at the priority of lvl, through the richest
+// interface implemented by sl
+func (d *toSystemdLogger) send(lvl Level, msg string) error {
+	if buildtags.VeryQuietEnabled {
+		return nil // the Fatal and Panic paths still exit and panic
+	}
+	r := GetRedactor()
+	msg = r.Message(msg)
+	fields := JournalFields(r.Fields(d.fields))
+	if d.err != nil {
+		fields["ERROR"] = r.Message(d.err.Error())
+	}
+
+	prio := lvl.SyslogPriority()
+	if fl, ok := d.sl.(SystemdFieldsLogger); ok {
+		return fl.Send(prio, msg, fields)
+	}
+
+	msg = appendJournalFields(msg, fields)
+	if ex, ok := d.sl.(SystemdLoggerEx); ok {
+		switch prio {
+		case 0:
+			return ex.Emerg(msg)
+		case 1:
+			return ex.Alert(msg)
+		case 2:
+			return ex.Crit(msg)
+		case 3:
+			return ex.Error(msg)
+		case 4:
+			return ex.Warning(msg)
+		case 5:
+			return ex.Notice(msg)
+		case 6:
+			return ex.Info(msg)
+		default:
+			return ex.Debug(msg)
 		}
 	}
+	switch {
+	case prio <= 3:
+		return d.sl.Error(msg)
+	case prio == 4:
+		return d.sl.Warning(msg)
+	default:
+		return d.sl.Info(msg)
+	}
 }
-func (d *toSystemdLogger) Debug(args ...interface{}) {
-	if d.lvl >= DebugLevel {
-		_ = d.sl.Info(args...)
-		if d.old != nil {
-			AsL(d.old).Debug(args...)
-		}
+
+// mirror returns the mirror or fallback logger carrying the fields and
+// error if the entry should be written to it, or nil. err is the
+// result of send.
+func (d *toSystemdLogger) mirror(err error) Logger {
+	l := d.st.old
+	if l == nil || d.st.mode == systemdNoMirror || d.st.mode == systemdFallback && err == nil {
+		return nil
+	}
+	if sl, ok := l.(SL); ok && len(d.fields) > 0 {
+		l = sl.WithFields(d.fields)
 	}
+	if el, ok := l.(EL); ok && d.err != nil {
+		l = el.WithError(d.err)
+	}
+	return l
 }
-func (d *toSystemdLogger) Info(args ...interface{}) {
-	_ = d.sl.Info(args...)
-	if d.old != nil {
-		AsL(d.old).Info(args...)
+
+// out writes msg at lvl if it is allowed by the level, then passes the
+// mirror logger to fn if any
+func (d *toSystemdLogger) out(lvl Level, msg string, fn func(l Logger)) {
+	if !d.GetLevel().Allows(lvl) {
+		return
 	}
+	if l := d.mirror(d.send(lvl, msg)); l != nil {
+		fn(l)
+	}
+}
+
+func (d *toSystemdLogger) Trace(args ...interface{}) {
+	d.out(TraceLevel, fmt.Sprint(args...), func(l Logger) { AsL(l).Trace(args...) })
+}
+func (d *toSystemdLogger) Debug(args ...interface{}) {
+	d.out(DebugLevel, fmt.Sprint(args...), func(l Logger) { AsL(l).Debug(args...) })
+}
+func (d *toSystemdLogger) Info(args ...interface{}) {
+	d.out(InfoLevel, fmt.Sprint(args...), func(l Logger) { AsL(l).Info(args...) })
 }
 func (d *toSystemdLogger) Warn(args ...interface{}) {
-	_ = d.sl.Warning(args...)
-	if d.old != nil {
-		AsL(d.old).Warn(args...)
-	}
+	d.out(WarnLevel, fmt.Sprint(args...), func(l Logger) { AsL(l).Warn(args...) })
 }
 func (d *toSystemdLogger) Error(args ...interface{}) {
-	_ = d.sl.Error(args...)
-	if d.old != nil {
-		AsL(d.old).Error(args...)
-	}
+	d.out(ErrorLevel, fmt.Sprint(args...), func(l Logger) { AsL(l).Error(args...) })
 }
 
 func (d *toSystemdLogger) Fatal(args ...interface{}) {
-	d.Error(args...)
-	if InTesting() {
-		panic(fmt.Sprint(args...))
+	if l := d.mirror(d.send(FatalLevel, fmt.Sprint(args...))); l != nil {
+		AsL(l).Error(args...)
 	}
-	os.Exit(1)
+	exitOnFatal(fmt.Sprint(args...))
 }
 
 func (d *toSystemdLogger) Panic(args ...interface{}) {
-	d.Error(args...)
+	if l := d.mirror(d.send(PanicLevel, fmt.Sprint(args...))); l != nil {
+		AsL(l).Error(args...)
+	}
 	panic(fmt.Sprint(args...))
 }
+
+// Print writes at InfoLevel whatever the level is, like the std logger
 func (d *toSystemdLogger) Print(args ...interface{}) {
-	_ = d.sl.Info(args...)
-	if d.old != nil {
-		AsL(d.old).Info(args...)
+	if l := d.mirror(d.send(InfoLevel, fmt.Sprint(args...))); l != nil {
+		AsL(l).Print(args...)
 	}
 }
+
+// Println is Print with the operands separated by spaces, like fmt.Sprintln
 func (d *toSystemdLogger) Println(args ...interface{}) {
-	_ = d.sl.Info(args...)
-	if d.old != nil {
-		AsL(d.old).Info(args...)
+	if l := d.mirror(d.send(InfoLevel, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))); l != nil {
+		AsL(l).Println(args...)
 	}
 }
+
 func (d *toSystemdLogger) Tracef(msg string, args ...interface{}) {
-	if d.lvl >= TraceLevel {
-		_ = d.sl.Infof(msg, args...)
-		if d.old != nil {
-			d.old.Tracef(msg, args...)
-		}
-	}
+	d.out(TraceLevel, fmt.Sprintf(msg, args...), func(l Logger) { l.Tracef(msg, args...) })
 }
 func (d *toSystemdLogger) Debugf(msg string, args ...interface{}) {
-	if d.lvl >= DebugLevel {
-		_ = d.sl.Infof(msg, args...)
-		if d.old != nil {
-			d.old.Debugf(msg, args...)
-		}
-	}
+	d.out(DebugLevel, fmt.Sprintf(msg, args...), func(l Logger) { l.Debugf(msg, args...) })
 }
 func (d *toSystemdLogger) Infof(msg string, args ...interface{}) {
-	_ = d.sl.Infof(msg, args...)
-	if d.old != nil {
-		d.old.Infof(msg, args...)
-	}
+	d.out(InfoLevel, fmt.Sprintf(msg, args...), func(l Logger) { l.Infof(msg, args...) })
 }
 func (d *toSystemdLogger) Warnf(msg string, args ...interface{}) {
-	_ = d.sl.Warningf(msg, args...)
-	if d.old != nil {
-		d.old.Warnf(msg, args...)
-	}
+	d.out(WarnLevel, fmt.Sprintf(msg, args...), func(l Logger) { l.Warnf(msg, args...) })
 }
 func (d *toSystemdLogger) Errorf(msg string, args ...interface{}) {
-	_ = d.sl.Errorf(msg, args...)
-	if d.old != nil {
-		d.old.Errorf(msg, args...)
-	}
+	d.out(ErrorLevel, fmt.Sprintf(msg, args...), func(l Logger) { l.Errorf(msg, args...) })
 }
 
 func (d *toSystemdLogger) Fatalf(msg string, args ...interface{}) {
-	// panic("implement me")
-	d.Errorf(msg, args...)
-	if InTesting() {
-		panic(fmt.Sprintf(msg, args...))
+	if l := d.mirror(d.send(FatalLevel, fmt.Sprintf(msg, args...))); l != nil {
+		l.Errorf(msg, args...)
 	}
-	os.Exit(1)
+	exitOnFatal(fmt.Sprintf(msg, args...))
 }
 
 func (d *toSystemdLogger) Panicf(msg string, args ...interface{}) {
-	d.Errorf(msg, args...)
+	if l := d.mirror(d.send(PanicLevel, fmt.Sprintf(msg, args...))); l != nil {
+		l.Errorf(msg, args...)
+	}
 	panic(fmt.Sprintf(msg, args...))
 }
 func (d *toSystemdLogger) Printf(msg string, args ...interface{}) {
@@ -147,9 +277,107 @@ func (d *toSystemdLogger) Printf(msg string, args ...interface{}) {
 	}
 	d.Infof(msg, args...)
 }
-func (d *toSystemdLogger) SetLevel(lvl Level)         { d.lvl = lvl }
-func (d *toSystemdLogger) GetLevel() Level            { return d.lvl }
+
+// Logf implements LL interface, so that NoticeLevel and CriticalLevel
+// are written at the notice and crit priorities.
+func (d *toSystemdLogger) Logf(lvl Level, msg string, args ...interface{}) {
+	d.out(lvl, fmt.Sprintf(msg, args...), func(l Logger) {
+		if ll, ok := l.(LL); ok {
+			ll.Logf(lvl, msg, args...)
+		}
+	})
+}
+
+// SetLevel sets the level of d, its parent and children. The mirror
+// or fallback logger keeps its own level.
+func (d *toSystemdLogger) SetLevel(lvl Level)         { atomic.StoreInt32(&d.st.lvl, int32(lvl)) }
+func (d *toSystemdLogger) GetLevel() Level            { return Level(atomic.LoadInt32(&d.st.lvl)) }
 func (d *toSystemdLogger) SetOutput(out io.Writer)    { d.w = out }
 func (d *toSystemdLogger) GetOutput() (out io.Writer) { return d.w }
 func (d *toSystemdLogger) Setup()                     {}
 func (d *toSystemdLogger) AddSkip(skip int) Logger    { return d }
+
+// JournalFields converts the fields of hedzr/log into the
+// journald-style fields: a key is upper-cased, the characters other
+// than A-Z, 0-9 and '_' are replaced with '_', and the leading '_' and
+// digits, which are reserved or invalid for journald, are stripped. A
+// value is formatted by fmt.Sprint, or Error() for an error.
+//
+// For example, {"user.id": 7, "err": io.EOF} becomes {"USER_ID": "7",
+// "ERR": "EOF"}. The fields with an empty key are dropped.
+//
+// The keys which journald or the logger itself sets for an entry,
+// listed in ReservedJournalFields (MESSAGE, PRIORITY, CODE_FILE,
+// SYSLOG_IDENTIFIER, ERROR, ...), are prefixed with "F_", so that
+// With("message", ...) is sent as F_MESSAGE and never replaces the
+// message itself.
+func JournalFields(fields map[string]interface{}) map[string]string {
+	m := make(map[string]string, len(fields))
+	for k, v := range fields {
+		key := journalKey(k)
+		if key == "" {
+			continue
+		}
+		if ReservedJournalFields[key] {
+			key = "F_" + key
+		}
+		if err, ok := v.(error); ok {
+			m[key] = err.Error()
+		} else {
+			m[key] = fmt.Sprint(v)
+		}
+	}
+	return m
+}
+
+// ReservedJournalFields are the journald fields which carry the
+// entry itself, and ERROR, which is set by WithError. JournalFields
+// prefixes a user key among them with "F_".
+var ReservedJournalFields = map[string]bool{
+	"MESSAGE":           true,
+	"MESSAGE_ID":        true,
+	"PRIORITY":          true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+	"ERRNO":             true,
+	"TID":               true,
+	"SYSLOG_FACILITY":   true,
+	"SYSLOG_IDENTIFIER": true,
+	"SYSLOG_PID":        true,
+	"SYSLOG_TIMESTAMP":  true,
+	"SYSLOG_RAW":        true,
+	"ERROR":             true,
+}
+
+func journalKey(k string) string {
+	b := []byte(strings.ToUpper(k))
+	for i, c := range b {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			b[i] = '_'
+		}
+	}
+	return strings.TrimLeft(string(b), "_0123456789")
+}
+
+// appendJournalFields appends the fields to msg as "KEY=value", in the
+// order of the keys
+func appendJournalFields(msg string, fields map[string]string) string {
+	if len(fields) == 0 {
+		return msg
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for _, k := range keys {
+		sb.WriteByte(' ')
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(fields[k])
+	}
+	return sb.String()
+}
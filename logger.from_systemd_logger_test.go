@@ -0,0 +1,215 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exSL records the priorities and messages written to a
+// SystemdLoggerEx
+type exSL struct {
+	recordSL
+	prios []int
+}
+
+func (r *exSL) at(prio int, v ...interface{}) error {
+	r.prios = append(r.prios, prio)
+	return r.rec(v...)
+}
+
+func (r *exSL) Emerg(v ...interface{}) error   { return r.at(0, v...) }
+func (r *exSL) Alert(v ...interface{}) error   { return r.at(1, v...) }
+func (r *exSL) Crit(v ...interface{}) error    { return r.at(2, v...) }
+func (r *exSL) Error(v ...interface{}) error   { return r.at(3, v...) }
+func (r *exSL) Warning(v ...interface{}) error { return r.at(4, v...) }
+func (r *exSL) Notice(v ...interface{}) error  { return r.at(5, v...) }
+func (r *exSL) Info(v ...interface{}) error    { return r.at(6, v...) }
+func (r *exSL) Debug(v ...interface{}) error   { return r.at(7, v...) }
+
+func (r *exSL) Emergf(format string, a ...interface{}) error {
+	return r.Emerg(fmt.Sprintf(format, a...))
+}
+func (r *exSL) Alertf(format string, a ...interface{}) error {
+	return r.Alert(fmt.Sprintf(format, a...))
+}
+func (r *exSL) Critf(format string, a ...interface{}) error { return r.Crit(fmt.Sprintf(format, a...)) }
+func (r *exSL) Noticef(format string, a ...interface{}) error {
+	return r.Notice(fmt.Sprintf(format, a...))
+}
+func (r *exSL) Debugf(format string, a ...interface{}) error {
+	return r.Debug(fmt.Sprintf(format, a...))
+}
+
+// fieldsSL records the structured entries written to a
+// SystemdFieldsLogger
+type fieldsSL struct {
+	exSL
+	fields []map[string]string
+}
+
+func (r *fieldsSL) Send(priority int, msg string, fields map[string]string) error {
+	r.fields = append(r.fields, fields)
+	return r.at(priority, msg)
+}
+
+func newTestSystemdLogger(sl SystemdLogger) *toSystemdLogger {
+	l := FromSystemdLogger(sl).(*toSystemdLogger)
+	l.SetLevel(TraceLevel)
+	return l
+}
+
+func TestFromSystemdLogger_fields(t *testing.T) {
+	requireOutput(t)
+	sl := &recordSL{}
+	l := newTestSystemdLogger(sl)
+	l.WithError(io.EOF).(SL).With("user.id", 7).Warnf("login %s", "failed")
+	l.Debugf("plain")
+	if strings.Join(sl.msgs, "|") != "login failed ERROR=EOF USER_ID=7|plain" {
+		t.Fatalf("bad messages: %q", sl.msgs)
+	}
+}
+
+func TestFromSystemdLogger_priorities(t *testing.T) {
+	requireOutput(t)
+	sl := &exSL{}
+	l := newTestSystemdLogger(sl)
+	l.Tracef("trace")
+	l.Debug("debug")
+	l.Infof("info")
+	l.Logf(NoticeLevel, "notice")
+	l.Warn("warn")
+	l.Errorf("error")
+	l.Logf(CriticalLevel, "critical")
+	if want := []int{7, 7, 6, 5, 4, 3, 2}; !reflect.DeepEqual(sl.prios, want) {
+		t.Fatalf("bad priorities %v, want %v", sl.prios, want)
+	}
+
+	l.SetLevel(InfoLevel)
+	l.Debugf("hidden")
+	l.Logf(NoticeLevel, "shown")
+	if n := len(sl.msgs); n != 8 || sl.msgs[n-1] != "shown" {
+		t.Fatalf("bad gating: %q", sl.msgs)
+	}
+}
+
+func TestFromSystemdLogger_structured(t *testing.T) {
+	requireOutput(t)
+	SetRedactor(newTestRedactor(t))
+	defer SetRedactor(nil)
+
+	sl := &fieldsSL{}
+	l := newTestSystemdLogger(sl)
+	l.WithError(io.EOF).(SL).WithFields(map[string]interface{}{"req-id": "a1", "password": "p4ss"}).Errorf("failed")
+	want := map[string]string{"REQ_ID": "a1", "PASSWORD": "***", "ERROR": "EOF"}
+	if len(sl.fields) != 1 || !reflect.DeepEqual(sl.fields[0], want) || sl.prios[0] != 3 || sl.msgs[0] != "failed" {
+		t.Fatalf("bad entry: %v %v %q", sl.prios, sl.fields, sl.msgs)
+	}
+}
+
+// failSL is a SystemdLogger which fails while down is true
+type failSL struct {
+	recordSL
+	down bool
+}
+
+func (r *failSL) Info(v ...interface{}) error {
+	if r.down {
+		return io.ErrClosedPipe
+	}
+	return r.rec(v...)
+}
+
+func TestFromSystemdLogger_levels(t *testing.T) {
+	requireOutput(t)
+	sl := &recordSL{}
+	l := newTestSystemdLogger(sl)
+	child := l.With("k", 1)
+	l.SetLevel(ErrorLevel)
+	l.Infof("info")
+	AsL(l).Warn("warn")
+	child.Warnf("warn")
+	l.Logf(NoticeLevel, "notice")
+	child.Errorf("error")
+	l.Logf(CriticalLevel, "critical")
+	if strings.Join(sl.msgs, "|") != "error K=1|critical" {
+		t.Fatalf("bad gating: %q", sl.msgs)
+	}
+
+	AsL(child).Print("print")
+	AsL(l).Println("println", 2)
+	if strings.Join(sl.msgs[2:], "|") != "print K=1|println 2" {
+		t.Fatalf("Print and Println should ignore the level: %q", sl.msgs)
+	}
+	sl.msgs = sl.msgs[:2]
+
+	child.SetLevel(OffLevel)
+	l.Errorf("off")
+	if l.GetLevel() != OffLevel || len(sl.msgs) != 2 {
+		t.Fatalf("the level should be shared with the children: %v %q", l.GetLevel(), sl.msgs)
+	}
+}
+
+func TestFromSystemdLogger_mirror(t *testing.T) {
+	requireOutput(t)
+	for _, c := range []struct {
+		opt  SystemdOpt
+		want string
+	}{
+		{nil, ""},
+		{WithSystemdMirror(newStdLogger()), "first|second"},
+		{WithSystemdFallback(newStdLogger()), "second"},
+	} {
+		sl := &failSL{}
+		var opts []SystemdOpt
+		if c.opt != nil {
+			opts = append(opts, c.opt)
+		}
+		l := FromSystemdLogger(sl, opts...)
+		l.SetLevel(InfoLevel)
+		out := captureStdOutput(func() {
+			l.Infof("first")
+			l.Debugf("hidden")
+			sl.down = true
+			l.Infof("second")
+		})
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			if i := strings.LastIndexByte(line, ' '); line != "" {
+				got = append(got, line[i+1:])
+			}
+		}
+		if strings.Join(got, "|") != c.want || strings.Join(sl.msgs, "|") != "first" {
+			t.Fatalf("want %q, got %q %q", c.want, out, sl.msgs)
+		}
+	}
+}
+
+func TestFromSystemdLogger_mirrorLevel(t *testing.T) {
+	mirror := newStdLoggerWith(WarnLevel)
+	l := FromSystemdLogger(&recordSL{}, WithSystemdMirror(mirror))
+	l.SetLevel(DebugLevel)
+	if l.GetLevel() != DebugLevel || mirror.GetLevel() != WarnLevel {
+		t.Fatalf("the mirror logger should keep its own level: %v %v", l.GetLevel(), mirror.GetLevel())
+	}
+}
+
+func TestJournalFields(t *testing.T) {
+	got := JournalFields(map[string]interface{}{
+		"user.id": 7, "err": io.EOF, "_private": 1, "2fa": true, "Trace-ID": "x", "__": 0,
+	})
+	want := map[string]string{"USER_ID": "7", "ERR": "EOF", "PRIVATE": "1", "FA": "true", "TRACE_ID": "x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	got = JournalFields(map[string]interface{}{
+		"message": "m", "priority": 1, "code_file": "f", "syslog_identifier": "id", "error": "e", "message_text": "t",
+	})
+	want = map[string]string{"F_MESSAGE": "m", "F_PRIORITY": "1", "F_CODE_FILE": "f", "F_SYSLOG_IDENTIFIER": "id", "F_ERROR": "e", "MESSAGE_TEXT": "t"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("reserved keys: got %v, want %v", got, want)
+	}
+}
@@ -3,67 +3,116 @@
 
 package log
 
-import "fmt"
-
 // VeryQuietEnabled identify whether `--tags=veryquiet` has been defined in go building
 var VeryQuietEnabled = false
 
 // Tracef prints the text to stdin if logging level is greater than TraceLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Tracef(msg string, args ...interface{}) {
-	logger.Tracef(msg, args...)
+	GetLogger().Tracef(msg, args...)
 }
 
 // Debugf prints the text to stdin if logging level is greater than DebugLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Debugf(msg string, args ...interface{}) {
-	logger.Debugf(msg, args...)
+	GetLogger().Debugf(msg, args...)
 }
 
 // Infof prints the text to stdin if logging level is greater than InfoLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Infof(msg string, args ...interface{}) {
-	logger.Infof(msg, args...)
+	GetLogger().Infof(msg, args...)
 }
 
-// Warnf prints the text to stderr
+// Warnf prints the text to stderr if logging level is greater than WarnLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Warnf(msg string, args ...interface{}) {
-	logger.Warnf(msg, args...)
+	GetLogger().Warnf(msg, args...)
 }
 
-// Errorf prints the text to stderr
+// Errorf prints the text to stderr if logging level is greater than ErrorLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Errorf(msg string, args ...interface{}) {
-	logger.Errorf(msg, args...)
+	GetLogger().Errorf(msg, args...)
+}
+
+// ErrorfWith prints the text and the rendered err to stderr.
+// The error chain, attached errors and stack trace of err will be
+// rendered too, see also WithError.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func ErrorfWith(err error, msg string, args ...interface{}) {
+	WithError(err).Errorf(msg, args...)
+}
+
+// Noticef prints the text at NoticeLevel, see Logf.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func Noticef(msg string, args ...interface{}) {
+	Logf(NoticeLevel, msg, args...)
+}
+
+// Criticalf prints the text at CriticalLevel, see Logf.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func Criticalf(msg string, args ...interface{}) {
+	Logf(CriticalLevel, msg, args...)
+}
+
+// Logf prints the text at lvl, which can be NoticeLevel, CriticalLevel
+// or a custom level registered by RegisterLevel. It never exits or
+// panics, even for FatalLevel and PanicLevel.
+//
+// If the package-level logger doesn't implement LL interface, the
+// text is printed at the closest built-in level which is not more
+// severe than lvl, such as InfoLevel for NoticeLevel.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func Logf(lvl Level, msg string, args ...interface{}) {
+	l := GetLogger()
+	if ll, ok := l.(LL); ok {
+		ll.Logf(lvl, msg, args...)
+		return
+	}
+	if lvl.Severity() < 0 {
+		return // OffLevel or an unknown level
+	}
+	switch lvl.builtin() {
+	case PanicLevel, FatalLevel, ErrorLevel:
+		l.Errorf(msg, args...)
+	case WarnLevel:
+		l.Warnf(msg, args...)
+	case InfoLevel:
+		l.Infof(msg, args...)
+	case DebugLevel:
+		l.Debugf(msg, args...)
+	default:
+		l.Tracef(msg, args...)
+	}
 }
 
 // Fatalf is equivalent to Printf() followed by a call to os.Exit(1).
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Fatalf(msg string, args ...interface{}) {
-	if InTesting() {
-		logger.Panicf(msg, args...)
+	if InTesting() && !hasExitFunc() {
+		GetLogger().Panicf(msg, args...)
 	}
-	logger.Fatalf(msg, args...)
+	GetLogger().Fatalf(msg, args...)
 }
 
 // Panicf is equivalent to Printf() followed by a call to panic().
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Panicf(msg string, args ...interface{}) {
-	logger.Panicf(msg, args...)
+	GetLogger().Panicf(msg, args...)
 }
 
 // Printf calls Output to print to the standard logger.
 // Arguments are handled in the manner of fmt.Printf.
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Printf(msg string, args ...interface{}) {
-	logger.Printf(msg, args...)
+	GetLogger().Printf(msg, args...)
 }
 
 // Trace prints all args to stdin if logging level is greater than TraceLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Trace(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Trace(args...)
 	}
 }
@@ -71,7 +120,7 @@ func Trace(args ...interface{}) {
 // Debug prints all args to stdin if logging level is greater than DebugLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Debug(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Debug(args...)
 	}
 }
@@ -79,23 +128,23 @@ func Debug(args ...interface{}) {
 // Info prints all args to stdin if logging level is greater than InfoLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Info(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Info(args...)
 	}
 }
 
-// Warn prints all args to stderr
+// Warn prints all args to stderr if logging level is greater than WarnLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Warn(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Warn(args...)
 	}
 }
 
-// Error prints all args to stderr
+// Error prints all args to stderr if logging level is greater than ErrorLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Error(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Error(args...)
 	}
 }
@@ -103,34 +152,17 @@ func Error(args ...interface{}) {
 // Fatal is equivalent to Printf() followed by a call to os.Exit(1).
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 //
-// If all args are nil, Fatal will return to caller normally.
+// If all args are nil, Fatal returns to caller normally, so that
+// log.Fatal(err) is safe for a nil err. To check an error, FatalIfErr is
+// recommended, see also SetFatalErrorWrapping.
 func Fatal(args ...interface{}) {
-	if l := AsL(logger); l != nil {
-		var has bool
-		var cnt int
-		var ent interface{}
-		for _, e := range args {
-			if e != nil {
-				has, ent = true, e
-				cnt++
-			}
-		}
-
-		if !has {
-			return
-		}
-
-		if cnt == 1 {
-			str := fmt.Sprintf("Error occurred: %+v", ent)
-			if InTesting() {
-				l.Panic(str)
-			} else {
-				l.Fatal(str)
-			}
+	if l := AsL(GetLogger()); l != nil {
+		var ok bool
+		if args, ok = nilSafeArgs(args); !ok {
 			return
 		}
 
-		if InTesting() {
+		if InTesting() && !hasExitFunc() {
 			l.Panic(args...)
 		}
 		l.Fatal(args...)
@@ -140,25 +172,13 @@ func Fatal(args ...interface{}) {
 // Panic is equivalent to Printf() followed by a call to panic().
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 //
-// If all args are nil, Panic will return to caller normally.
+// If all args are nil, Panic returns to caller normally, so that
+// log.Panic(err) is safe for a nil err. To check an error, PanicIfErr is
+// recommended, see also SetFatalErrorWrapping.
 func Panic(args ...interface{}) {
-	if l := AsL(logger); l != nil {
-		var has bool
-		var cnt int
-		var ent interface{}
-		for _, e := range args {
-			if e != nil {
-				has, ent = true, e
-				cnt++
-			}
-		}
-
-		if !has {
-			return
-		}
-
-		if cnt == 1 {
-			l.Panic("Error occurred: %+v", ent)
+	if l := AsL(GetLogger()); l != nil {
+		var ok bool
+		if args, ok = nilSafeArgs(args); !ok {
 			return
 		}
 
@@ -166,11 +186,61 @@ func Panic(args ...interface{}) {
 	}
 }
 
+// FatalIfErr logs err and msg at FatalLevel and exits if err is not
+// nil, see also Fatal. It returns false if err is nil.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+//
+//	data, err := os.ReadFile(name)
+//	log.FatalIfErr(err, "cannot read", name)
+func FatalIfErr(err error, msg ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+	l := WithError(err)
+	str := errMessage(err, msg)
+	if InTesting() && !hasExitFunc() {
+		l.Panicf("%s", str)
+	}
+	l.Fatalf("%s", str)
+	return true
+}
+
+// PanicIfErr logs err and msg at PanicLevel and panics if err is not
+// nil, see also Panic. It returns false if err is nil.
+//
+// The panic value is an error which reads "msg: err" and unwraps to
+// err, so that the callers of recover() can inspect the original one.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func PanicIfErr(err error, msg ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+	l := WithError(err)
+	if ll, ok := l.(LL); ok {
+		ll.Logf(PanicLevel, "%s", errMessage(err, msg))
+	} else {
+		l.Errorf("%s", errMessage(err, msg))
+	}
+	panic(newIfErrError(err, msg))
+}
+
+// WarnIfErr logs err and msg at WarnLevel if err is not nil. It
+// returns true if err was logged.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func WarnIfErr(err error, msg ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+	l := WithError(err)
+	l.Warnf("%s", errMessage(err, msg))
+	return true
+}
+
 // Print calls Output to print to the standard logger.
 // Arguments are handled in the manner of fmt.Print.
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Print(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Print(args...)
 	}
 }
@@ -179,7 +249,7 @@ func Print(args ...interface{}) {
 // Arguments are handled in the manner of fmt.Println.
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Println(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Println(args...)
 	}
 }
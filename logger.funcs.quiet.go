@@ -3,6 +3,8 @@
 
 package log
 
+import "fmt"
+
 // VeryQuietEnabled identify whether `--tags=veryquiet` has been defined in go building
 var VeryQuietEnabled = true
 
@@ -24,31 +26,57 @@ func Infof(msg string, args ...interface{}) {
 	// logger.Infof(msg, args...)
 }
 
-// Warnf prints the text to stderr
+// Warnf prints the text to stderr if logging level is greater than WarnLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Warnf(msg string, args ...interface{}) {
 	// logger.Warnf(msg, args...)
 }
 
-// Errorf prints the text to stderr
+// Errorf prints the text to stderr if logging level is greater than ErrorLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Errorf(msg string, args ...interface{}) {
 	// logger.Errorf(msg, args...)
 }
 
-// Fatalf is equivalent to Printf() followed by a call to os.Exit(1).
+// Noticef prints the text at NoticeLevel, see Logf.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func Noticef(msg string, args ...interface{}) {
+	// Logf(NoticeLevel, msg, args...)
+}
+
+// Criticalf prints the text at CriticalLevel, see Logf.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func Criticalf(msg string, args ...interface{}) {
+	// Logf(CriticalLevel, msg, args...)
+}
+
+// Logf prints the text at lvl, which can be NoticeLevel, CriticalLevel
+// or a custom level registered by RegisterLevel.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func Logf(lvl Level, msg string, args ...interface{}) {
+	// logger.(LL).Logf(lvl, msg, args...)
+}
+
+// ErrorfWith prints the text and the rendered err to stderr.
+// The error chain, attached errors and stack trace of err will be
+// rendered too, see also WithError.
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
+func ErrorfWith(err error, msg string, args ...interface{}) {
+	// WithError(err).Errorf(msg, args...)
+}
+
+// Fatalf is equivalent to Printf() followed by a call to os.Exit(1).
+// Since `--tags=veryquiet` was defined, nothing is printed but it still
+// exits, see SetExitFunc.
 func Fatalf(msg string, args ...interface{}) {
-	// if InTesting() {
-	//	logger.Panicf(msg, args)
-	// }
-	// logger.Fatalf(msg, args...)
+	exitOnFatal(fmt.Sprintf(msg, args...))
 }
 
 // Panicf is equivalent to Printf() followed by a call to panic().
-// It would be optimized to discard if `--tags=veryquiet` was been defined.
+// Since `--tags=veryquiet` was defined, nothing is printed but it still
+// panics.
 func Panicf(msg string, args ...interface{}) {
-	// logger.Panicf(msg, args...)
+	panic(fmt.Sprintf(msg, args...))
 }
 
 // Printf calls Output to print to the standard logger.
@@ -82,7 +110,7 @@ func Info(args ...interface{}) {
 	// }
 }
 
-// Warn prints all args to stderr
+// Warn prints all args to stderr if logging level is greater than WarnLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Warn(args ...interface{}) {
 	// if l := AsL(logger); l != nil {
@@ -90,7 +118,7 @@ func Warn(args ...interface{}) {
 	// }
 }
 
-// Error prints all args to stderr
+// Error prints all args to stderr if logging level is greater than ErrorLevel
 // It would be optimized to discard if `--tags=veryquiet` was been defined.
 func Error(args ...interface{}) {
 	// if l := AsL(logger); l != nil {
@@ -99,22 +127,55 @@ func Error(args ...interface{}) {
 }
 
 // Fatal is equivalent to Printf() followed by a call to os.Exit(1).
-// It would be optimized to discard if `--tags=veryquiet` was been defined.
+// Since `--tags=veryquiet` was defined, nothing is printed but it still
+// exits, see SetExitFunc.
 func Fatal(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	if InTesting() {
-	//		l.Panic(args)
-	//	}
-	//	l.Fatal(args...)
-	// }
+	var ok bool
+	if args, ok = nilSafeArgs(args); ok {
+		exitOnFatal(fmt.Sprint(args...))
+	}
 }
 
 // Panic is equivalent to Printf() followed by a call to panic().
-// It would be optimized to discard if `--tags=veryquiet` was been defined.
+// Since `--tags=veryquiet` was defined, nothing is printed but it still
+// panics.
 func Panic(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Panic(args...)
+	var ok bool
+	if args, ok = nilSafeArgs(args); ok {
+		panic(fmt.Sprint(args...))
+	}
+}
+
+// FatalIfErr exits if err is not nil, see also Fatal. It returns false
+// if err is nil.
+// Since `--tags=veryquiet` was defined, nothing is printed.
+func FatalIfErr(err error, msg ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+	exitOnFatal(errMessage(err, msg))
+	return true
+}
+
+// PanicIfErr panics if err is not nil, see also Panic. It returns false
+// if err is nil. The panic value is an error which unwraps to err.
+// Since `--tags=veryquiet` was defined, nothing is printed.
+func PanicIfErr(err error, msg ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+	panic(newIfErrError(err, msg))
+}
+
+// WarnIfErr logs err and msg at WarnLevel if err is not nil. It
+// returns true if err was logged.
+// It would be optimized to discard if `--tags=veryquiet` was been defined.
+func WarnIfErr(err error, msg ...interface{}) bool {
+	// if err == nil {
+	//	return false
 	// }
+	// ...
+	return err != nil
 }
 
 // Print calls Output to print to the standard logger.
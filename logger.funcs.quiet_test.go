@@ -0,0 +1,106 @@
+//go:build veryquiet
+// +build veryquiet
+
+package log
+
+import (
+	"io"
+	"testing"
+)
+
+func TestVeryQuiet(t *testing.T) {
+	saved := GetLevel()
+	defer SetLevel(saved)
+	SetLevel(TraceLevel)
+
+	out := captureStdOutput(func() {
+		Tracef("tracef")
+		Infof("infof")
+		Errorf("errorf")
+		Noticef("noticef")
+		Logf(CriticalLevel, "logf")
+		Info("info")
+		Println("println")
+		WarnIfErr(io.EOF, "warn")
+		ErrorfWith(io.EOF, "errorfwith")
+
+		l := GetLogger()
+		l.Infof("method infof")
+		l.Errorf("method errorf")
+		AsL(l).Warn("method warn")
+		NewStdLoggerWithConfig(NewLoggerConfig(func(lc *LoggerConfig) { lc.Format = "json" })).Errorf("json")
+	})
+	if out != "" {
+		t.Fatalf("expect nothing printed in a veryquiet build, got %q", out)
+	}
+}
+
+func TestVeryQuiet_systemd(t *testing.T) {
+	saved := GetLogger()
+	defer SetLogger(saved)
+
+	var code int
+	SetExitFunc(func(c int) { code = c })
+	defer SetExitFunc(nil)
+
+	sl := &exSL{}
+	SetLogger(FromSystemdLogger(sl, WithSystemdMirror(newStdLogger())))
+	SetLevel(TraceLevel)
+	out := captureStdOutput(func() {
+		Debugf("debugf")
+		Errorf("errorf")
+		Logf(CriticalLevel, "logf")
+		AsL(GetLogger()).Warn("method warn")
+		GetLogger().(SL).With("k", 1).Infof("with")
+		GetLogger().Fatalf("fatal")
+	})
+	if out != "" || len(sl.msgs) != 0 || code != 1 {
+		t.Fatalf("expect nothing written to the system log, got %q %q, exit code %d", out, sl.msgs, code)
+	}
+}
+
+func TestVeryQuiet_fatalAndPanic(t *testing.T) {
+	var code int
+	SetExitFunc(func(c int) { code = c })
+	defer SetExitFunc(nil)
+
+	out := captureStdOutput(func() {
+		Fatalf("fatal %d", 1)
+		if code != 1 {
+			t.Fatalf("Fatalf should exit, got code %d", code)
+		}
+		code = 0
+		Fatal("fatal")
+		if code != 1 {
+			t.Fatalf("Fatal should exit, got code %d", code)
+		}
+		code = 0
+		if !FatalIfErr(io.EOF) || code != 1 {
+			t.Fatalf("FatalIfErr should exit, got code %d", code)
+		}
+		code = 0
+		GetLogger().Fatalf("fatal")
+		if code != 1 {
+			t.Fatalf("the logger should exit, got code %d", code)
+		}
+	})
+	if out != "" {
+		t.Fatalf("expect nothing printed, got %q", out)
+	}
+
+	for name, fn := range map[string]func(){
+		"Panicf":     func() { Panicf("panic %d", 1) },
+		"Panic":      func() { Panic("panic") },
+		"PanicIfErr": func() { PanicIfErr(io.EOF) },
+		"logger":     func() { GetLogger().Panicf("panic") },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("%s should panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -23,12 +23,88 @@ type (
 		Infof(format string, a ...interface{}) error
 	}
 
+	// SystemdLoggerEx is a SystemdLogger which supports all the
+	// syslog/journald priorities, from Emerg (0) to Debug (7).
+	//
+	// It is optional for a SystemdLogger, FromSystemdLogger detects it
+	// at runtime, so that Trace and Debug are not logged as Info.
+	SystemdLoggerEx interface {
+		SystemdLogger
+
+		Emerg(v ...interface{}) error
+		Alert(v ...interface{}) error
+		Crit(v ...interface{}) error
+		Notice(v ...interface{}) error
+		Debug(v ...interface{}) error
+
+		Emergf(format string, a ...interface{}) error
+		Alertf(format string, a ...interface{}) error
+		Critf(format string, a ...interface{}) error
+		Noticef(format string, a ...interface{}) error
+		Debugf(format string, a ...interface{}) error
+	}
+
+	// SystemdFieldsLogger writes a structured entry to the system log.
+	//
+	// It is optional for a SystemdLogger, FromSystemdLogger prefers it
+	// if detected at runtime. Otherwise, the fields are appended to the
+	// message as "KEY=value".
+	SystemdFieldsLogger interface {
+		// Send writes msg at priority, from 0 (emerg) to 7 (debug). The
+		// keys of fields are the journald-style names, see JournalFields.
+		Send(priority int, msg string, fields map[string]string) error
+	}
+
 	// SL provides a structural logging interface
 	SL interface {
 		With(key string, val interface{}) Logger
 		WithFields(fields map[string]interface{}) Logger
 	}
 
+	// EL provides an error-aware logging interface.
+	//
+	// It is optional for a Logger, use log.WithError to attach an
+	// error to the package-level logger safely.
+	EL interface {
+		// WithError returns a child logger which carries err, so that
+		// the error chain, attached errors and stack trace of err can
+		// be rendered within the logging entries.
+		WithError(err error) Logger
+	}
+
+	// LL provides logging at any level, including NoticeLevel,
+	// CriticalLevel and the custom levels registered by RegisterLevel.
+	//
+	// It is optional for a Logger, use log.Logf to log at a level
+	// safely.
+	LL interface {
+		// Logf prints the text if lvl is allowed by the logging level,
+		// see Level.Allows
+		Logf(lvl Level, msg string, args ...interface{})
+	}
+
+	// OL provides logging at any level with the error of the output
+	// device reported.
+	//
+	// It is optional for a Logger, AsSystemdLogger prefers it so that
+	// the write errors are returned to the caller.
+	OL interface {
+		// Output prints msg like Logf, and returns the error of the
+		// output device. It returns nil if lvl is not allowed.
+		Output(lvl Level, msg string) error
+	}
+
+	// CL re-applies a changed LoggerConfig to a live logger in place.
+	//
+	// It is optional for a Logger, WatchConfig uses it to apply the
+	// changes other than the level.
+	CL interface {
+		// ApplyConfig applies config, keys are the json/yaml keys of
+		// the changed settings. It returns an error without applying
+		// anything if one of them cannot be applied.
+		ApplyConfig(config *LoggerConfig, keys []string) error
+	}
+
 	// L provides a basic logger interface
 	L interface {
 
@@ -129,34 +205,34 @@ func AsLogger(logger L) Logger {
 }
 
 // SetLevel sets the logging level
-func SetLevel(l Level) { logger.SetLevel(l) }
+func SetLevel(l Level) { GetLogger().SetLevel(l) }
 
 // Setup _
 func Setup() {
-	logger.Setup()
+	GetLogger().Setup()
 }
 
 // GetLevel returns the current logging level
-func GetLevel() Level { return logger.GetLevel() }
+func GetLevel() Level { return GetLogger().GetLevel() }
 
 // SetOutput setup the logging output device
-func SetOutput(w io.Writer) { logger.SetOutput(w) }
+func SetOutput(w io.Writer) { GetLogger().SetOutput(w) }
 
 // GetOutput return the logging output device
-func GetOutput() (w io.Writer) { return logger.GetOutput() }
+func GetOutput() (w io.Writer) { return GetLogger().GetOutput() }
 
 // SetLogger transfer an instance into log package-level value
 func SetLogger(l Logger) {
-	l.SetLevel(logger.GetLevel())
-	logger = l
+	l.SetLevel(GetLogger().GetLevel())
+	setLogger(l)
 	log.SetOutput(l.GetOutput())
 }
 
 // GetLogger returns the package-level logger globally
-func GetLogger() Logger { return logger }
+func GetLogger() Logger { return logger.Load().(loggerBox).Logger }
 
 // Skip ignore some extra caller frames
 func Skip(skip int) Logger {
-	return logger.AddSkip(skip)
+	return GetLogger().AddSkip(skip)
 	// return logger
 }
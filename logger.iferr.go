@@ -0,0 +1,90 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// SetFatalErrorWrapping enables the legacy wrapping of the
+// package-level Fatal and Panic: a single non-nil arg is logged as
+// "Error occurred: ...". It is disabled by default.
+//
+// Fatal and Panic always return to caller normally if all args are
+// nil, so that log.Fatal(err) is safe for a nil err. To check an
+// error, FatalIfErr and PanicIfErr express the intent clearly.
+func SetFatalErrorWrapping(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&fatalErrorWrapping, v)
+}
+
+var fatalErrorWrapping int32
+
+// nilSafeArgs returns ok=false if all args are nil, and wraps a single
+// non-nil arg if SetFatalErrorWrapping is enabled
+func nilSafeArgs(args []interface{}) (ret []interface{}, ok bool) {
+	var cnt int
+	var ent interface{}
+	for _, e := range args {
+		if e != nil {
+			ent = e
+			cnt++
+		}
+	}
+
+	switch {
+	case cnt == 0:
+		return nil, false
+	case cnt == 1 && atomic.LoadInt32(&fatalErrorWrapping) != 0:
+		return []interface{}{fmt.Sprintf("Error occurred: %+v", ent)}, true
+	}
+	return args, true
+}
+
+// errMessage builds the message for the XxxIfErr functions. The error
+// text is appended to msg unless the package-level logger renders it
+// by itself, see EL.
+func errMessage(err error, msg []interface{}) string {
+	str := joinArgs(msg)
+	if _, ok := GetLogger().(EL); ok {
+		if str == "" {
+			str = "error occurred"
+		}
+		return str
+	}
+	if str == "" {
+		return err.Error()
+	}
+	return str + ": " + err.Error()
+}
+
+// joinArgs formats args like fmt.Sprintln, the operands are always
+// separated by spaces, without the trailing newline
+func joinArgs(args []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+// ifErrError is the value PanicIfErr panics with. It unwraps to the
+// checked error, so that the callers of recover() can inspect it.
+type ifErrError struct {
+	msg string
+	err error
+}
+
+func newIfErrError(err error, msg []interface{}) error {
+	return &ifErrError{msg: joinArgs(msg), err: err}
+}
+
+func (e *ifErrError) Error() string {
+	if e.msg == "" {
+		return e.err.Error()
+	}
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *ifErrError) Unwrap() error { return e.err }
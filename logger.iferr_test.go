@@ -0,0 +1,76 @@
+package log
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWarnIfErr(t *testing.T) {
+	requireOutput(t)
+	var handled bool
+	out := captureStdOutput(func() {
+		if WarnIfErr(nil, "never") {
+			t.Fatal("nil error should not be handled")
+		}
+		handled = WarnIfErr(io.EOF, "reading", "config")
+	})
+	if !handled {
+		t.Fatal("expect io.EOF handled")
+	}
+	if !strings.Contains(out, "logger.iferr_test.go:") || !strings.Contains(out, "reading config error=EOF") {
+		t.Fatalf("bad output: %q", out)
+	}
+}
+
+func TestFatalIfErr(t *testing.T) {
+	requireOutput(t)
+	var code int
+	SetExitFunc(func(c int) { code = c })
+	defer SetExitFunc(nil)
+
+	out := captureStdOutput(func() {
+		if FatalIfErr(nil) {
+			t.Fatal("nil error should not be handled")
+		}
+		if !FatalIfErr(io.EOF) {
+			t.Fatal("expect io.EOF handled")
+		}
+	})
+	if code != 1 || !strings.Contains(out, "error occurred error=EOF") {
+		t.Fatalf("bad exiting: code %d, output %q", code, out)
+	}
+}
+
+func TestPanicIfErr(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		if !ok {
+			t.Fatal("expect panic with an error")
+		}
+		if u, ok := err.(interface{ Unwrap() error }); !ok || u.Unwrap() != io.EOF || err.Error() != "bad input 3: EOF" {
+			t.Fatalf("the panic value should wrap the error: %v", err)
+		}
+	}()
+
+	_ = captureStdOutput(func() {
+		PanicIfErr(nil)
+		PanicIfErr(io.EOF, "bad", "input", 3)
+	})
+}
+
+func TestSetFatalErrorWrapping(t *testing.T) {
+	Fatal(nil, nil)
+	Panic(nil)
+	if args, ok := nilSafeArgs([]interface{}{nil, io.EOF}); !ok || args[1] != io.EOF {
+		t.Fatalf("bad args: %v", args)
+	}
+
+	SetFatalErrorWrapping(true)
+	defer SetFatalErrorWrapping(false)
+	Fatal(nil, nil)
+	Panic(nil)
+	if args, ok := nilSafeArgs([]interface{}{nil, io.EOF}); !ok || args[0] != "Error occurred: EOF" {
+		t.Fatalf("bad args: %v", args)
+	}
+}
@@ -1,10 +1,20 @@
 package log
 
-import "log"
+import (
+	"log"
+	"sync/atomic"
+)
 
 func init() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	logger = newStdLogger()
+	log.SetFlags(log.LstdFlags) // the caller is reported by stdLogger itself
+	setLogger(newStdLogger())
 }
 
-var logger Logger
+// logger holds the package-level logger as a loggerBox, so that it
+// can be replaced while logging in other goroutines
+var logger atomic.Value
+
+// loggerBox keeps the concrete type stored in logger unchanged
+type loggerBox struct{ Logger }
+
+func setLogger(l Logger) { logger.Store(loggerBox{l}) }
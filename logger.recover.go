@@ -0,0 +1,183 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+
+	"github.com/hedzr/log/closers"
+)
+
+// Recover recovers a panic and logs it through the package-level
+// logger with the stack trace and the goroutine id. It must be called
+// by defer directly:
+//
+//	func worker() {
+//	    defer log.Recover(log.WithRePanic())
+//	    // ...
+//	}
+//
+// By default the panic is swallowed after logged. WithRePanic and
+// WithFatalOnPanic change that, and WithCloseOnPanic calls
+// closers.Close() before re-panicking or exiting.
+//
+// Under go test, closers.Close() is skipped since it can be run only
+// once in a process, and the Fatal path panics instead of exiting,
+// just like Fatal does.
+func Recover(opts ...RecoverOpt) {
+	if r := recover(); r != nil {
+		handlePanic(r, opts...)
+	}
+}
+
+// GoSafe runs fn in a new goroutine and recovers the panic from it,
+// see Recover for the options.
+func GoSafe(fn func(), opts ...RecoverOpt) {
+	go func() {
+		defer Recover(opts...)
+		fn()
+	}()
+}
+
+// RecoverOpt is a functional option for Recover and GoSafe
+type RecoverOpt func(ro *recoverOptions)
+
+// WithRePanic panics again with the recovered value after logged
+func WithRePanic() RecoverOpt {
+	return func(ro *recoverOptions) {
+		ro.action = panicRePanic
+	}
+}
+
+// WithFatalOnPanic logs the recovered value at FatalLevel and exits
+// by the Fatal path of the package-level logger
+func WithFatalOnPanic() RecoverOpt {
+	return func(ro *recoverOptions) {
+		ro.action = panicFatal
+	}
+}
+
+// WithCloseOnPanic calls closers.Close() before re-panicking or
+// exiting, or after a swallowed panic logged if always is true
+func WithCloseOnPanic(always bool) RecoverOpt {
+	return func(ro *recoverOptions) {
+		ro.closeAll, ro.closeAlways = true, always
+	}
+}
+
+// WithPanicHandler calls handler with the recovered value after logged.
+// With WithFatalOnPanic, it is called before logging and exiting.
+func WithPanicHandler(handler func(v interface{})) RecoverOpt {
+	return func(ro *recoverOptions) {
+		ro.handler = handler
+	}
+}
+
+// WithRecoverLogger logs the recovered value through l instead of the
+// package-level logger, so that a child logger with its fields, such
+// as a request id, can be used
+func WithRecoverLogger(l Logger) RecoverOpt {
+	return func(ro *recoverOptions) {
+		ro.logger = l
+	}
+}
+
+// WithRePanicIf re-panics with the recovered value without logging it
+// if match reports true, such as for http.ErrAbortHandler which is
+// used by net/http to abort a response silently
+func WithRePanicIf(match func(v interface{}) bool) RecoverOpt {
+	return func(ro *recoverOptions) {
+		ro.rePanicIf = match
+	}
+}
+
+type recoverOptions struct {
+	logger      Logger
+	action      int
+	closeAll    bool
+	closeAlways bool
+	handler     func(v interface{})
+	rePanicIf   func(v interface{}) bool
+}
+
+const (
+	panicSwallow = iota
+	panicRePanic
+	panicFatal
+)
+
+func handlePanic(r interface{}, opts ...RecoverOpt) {
+	var ro recoverOptions
+	for _, opt := range opts {
+		opt(&ro)
+	}
+	if ro.rePanicIf != nil && ro.rePanicIf(r) {
+		panic(r)
+	}
+
+	pe := &panicError{value: r, goroutine: goroutineID(), stack: captureStack(0)}
+	if ro.logger == nil {
+		ro.logger = GetLogger()
+	}
+	l := withError(ro.logger, pe).With("goroutine", pe.goroutine)
+	if ro.action == panicFatal {
+		if ro.handler != nil {
+			ro.handler(r) // the Fatal path never returns
+		}
+		closeOnPanic(ro.closeAll)
+		l.Fatalf("recovered from panic: %v", r)
+		return
+	}
+
+	l.Errorf("recovered from panic: %v", r)
+	if ro.handler != nil {
+		ro.handler(r)
+	}
+	if ro.action == panicRePanic {
+		closeOnPanic(ro.closeAll)
+		panic(r)
+	}
+	closeOnPanic(ro.closeAll && ro.closeAlways)
+}
+
+func closeOnPanic(b bool) {
+	if b && !InTesting() {
+		closers.Close()
+	}
+}
+
+// panicError wraps a recovered value with the stack trace of the
+// panicking goroutine
+type panicError struct {
+	value     interface{}
+	goroutine uint64
+	stack     []string
+}
+
+func (e *panicError) Error() string { return fmt.Sprintf("panic: %v", e.value) }
+
+// Unwrap returns the recovered value if it is an error
+func (e *panicError) Unwrap() error {
+	if err, ok := e.value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+func (e *panicError) capturedStack() []string { return e.stack }
+
+// goroutineID parses the id of the current goroutine from the header
+// line of runtime.Stack, "goroutine 18 [running]:"
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		id, _ := strconv.ParseUint(string(buf[:i]), 10, 64)
+		return id
+	}
+	return 0
+}
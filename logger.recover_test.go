@@ -0,0 +1,90 @@
+package log
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	requireOutput(t)
+	var handled interface{}
+	out := captureStdOutput(func() {
+		defer Recover(WithPanicHandler(func(v interface{}) { handled = v }))
+		panicHere()
+	})
+	t.Log(out)
+
+	if handled != "boom" {
+		t.Fatalf("expect the handler called with the recovered value, but got %v", handled)
+	}
+	for _, s := range []string{"logger.recover_test.go:", "recovered from panic: boom", "goroutine=", `error="panic: boom"`, "log.panicHere"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expect %q in output", s)
+		}
+	}
+}
+
+func TestRecover_rePanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r != io.EOF {
+			t.Fatalf("expect re-panic with io.EOF, but got %v", r)
+		}
+	}()
+
+	_ = captureStdOutput(func() {
+		defer Recover(WithRePanic(), WithCloseOnPanic(false))
+		panic(io.EOF)
+	})
+}
+
+func TestRecover_fatal(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expect Fatal path panics under testing")
+		}
+	}()
+
+	_ = captureStdOutput(func() {
+		defer Recover(WithFatalOnPanic())
+		panic("fatal")
+	})
+}
+
+func TestRecover_fatalHandler(t *testing.T) {
+	var handled interface{}
+	defer func() {
+		if r := recover(); r == nil || handled != "fatal" {
+			t.Fatalf("expect the handler called before the Fatal path, but got %v, %v", handled, r)
+		}
+	}()
+
+	_ = captureStdOutput(func() {
+		defer Recover(WithFatalOnPanic(), WithPanicHandler(func(v interface{}) { handled = v }))
+		panic("fatal")
+	})
+}
+
+func TestGoSafe(t *testing.T) {
+	requireOutput(t)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	out := captureStdOutput(func() {
+		GoSafe(func() { panic("in goroutine") }, WithPanicHandler(func(v interface{}) { wg.Done() }))
+		wg.Wait()
+	})
+	if !strings.Contains(out, "recovered from panic: in goroutine") {
+		t.Fatalf("bad output: %q", out)
+	}
+}
+
+func TestGoroutineID(t *testing.T) {
+	if goroutineID() == 0 {
+		t.Fatal("expect a goroutine id")
+	}
+}
+
+func panicHere() {
+	panic("boom")
+}
@@ -0,0 +1,298 @@
+// Copyright © 2020 Hedzr Yeh.
+
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hedzr/log/dir"
+)
+
+// DefaultRedactKeys are the common names of the sensitive fields,
+// they can be passed to NewRedactor.
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secret", "*token*", "authorization",
+	"api_key", "apikey", "cookie", "set-cookie",
+}
+
+// DefaultRedactMask replaces the sensitive values
+const DefaultRedactMask = "***"
+
+// Redactor masks the sensitive values before they are written:
+//
+//   - the value of a field is masked if its key matches one of the
+//     keys, which are case-insensitive globs with '*' and '?' (see
+//     dir.IsWildMatch). The nested maps with string keys, such as
+//     http.Header, and the slices are redacted too;
+//   - the pointers and the structs, such as an *http.Request, are
+//     walked too: an exported struct field is masked if its name or
+//     its json tag matches one of the keys. A struct with something
+//     masked is written as a map of its exported fields, the others
+//     are kept as is;
+//   - the substrings of a message, or of a string, error or
+//     fmt.Stringer field, matching one
+//     of the patterns are masked. If a pattern has capturing groups,
+//     only the groups are masked, so `(?i)password=(\S+)` keeps the
+//     "password=" part.
+//
+// A nil *Redactor redacts nothing.
+type Redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+	mask     string
+}
+
+// NewRedactor returns a Redactor with the field keys and the message
+// patterns, it returns an error if a pattern cannot be compiled.
+func NewRedactor(keys []string, patterns ...string) (*Redactor, error) {
+	r := &Redactor{mask: DefaultRedactMask}
+	for _, k := range keys {
+		r.keys = append(r.keys, strings.ToLower(k))
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// WithMask replaces DefaultRedactMask with mask
+func (r *Redactor) WithMask(mask string) *Redactor {
+	r.mask = mask
+	return r
+}
+
+// SetRedactor sets the Redactor used by the built-in std logger, its
+// JSON formatter and the systemd adapter (FromSystemdLogger). Passing
+// nil disables the redaction.
+func SetRedactor(r *Redactor) {
+	redactor.Store(&r)
+}
+
+// GetRedactor returns the Redactor set by SetRedactor
+func GetRedactor() *Redactor {
+	if p, ok := redactor.Load().(**Redactor); ok {
+		return *p
+	}
+	return nil
+}
+
+var redactor atomic.Value
+
+// MatchKey tests if the value of the field key should be masked
+func (r *Redactor) MatchKey(key string) bool {
+	if r == nil {
+		return false
+	}
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if k == key || dir.IsWildMatch(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Message masks the substrings of msg matching the patterns
+func (r *Redactor) Message(msg string) string {
+	if r == nil {
+		return msg
+	}
+	for _, re := range r.patterns {
+		if re.NumSubexp() == 0 {
+			msg = re.ReplaceAllLiteralString(msg, r.mask)
+			continue
+		}
+		msg = r.maskGroups(re, msg)
+	}
+	return msg
+}
+
+// maskGroups masks the capturing groups of the matches of re
+func (r *Redactor) maskGroups(re *regexp.Regexp, msg string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(msg, -1) {
+		for g := 2; g+1 < len(m); g += 2 {
+			if m[g] < last || m[g+1] <= m[g] {
+				continue // unmatched, empty or nested group
+			}
+			sb.WriteString(msg[last:m[g]])
+			sb.WriteString(r.mask)
+			last = m[g+1]
+		}
+	}
+	if last == 0 {
+		return msg
+	}
+	sb.WriteString(msg[last:])
+	return sb.String()
+}
+
+// Field returns the redacted value of the field key
+func (r *Redactor) Field(key string, val interface{}) interface{} {
+	if r == nil {
+		return val
+	}
+	if r.MatchKey(key) {
+		return r.mask
+	}
+	var masked bool
+	return r.value(val, 0, &masked)
+}
+
+// Fields returns a redacted copy of fields, or fields itself if r is
+// nil
+func (r *Redactor) Fields(fields map[string]interface{}) map[string]interface{} {
+	if r == nil || len(fields) == 0 {
+		return fields
+	}
+	m := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		m[k] = r.Field(k, v)
+	}
+	return m
+}
+
+// value returns the redacted val, masked is set if anything in val is
+// masked
+func (r *Redactor) value(val interface{}, depth int, masked *bool) interface{} {
+	switch v := val.(type) {
+	case string:
+		return r.message(v, masked)
+	case error:
+		return r.message(v.Error(), masked)
+	case fmt.Stringer:
+		// keep the value, such as a time.Time, if nothing is masked
+		if str, msg := v.String(), r.Message(v.String()); msg != str {
+			*masked = true
+			return msg
+		}
+		return val
+	case nil:
+		return val
+	}
+
+	rv := reflect.ValueOf(val)
+	if depth >= maxErrorDepth {
+		return val
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return val // []byte
+		}
+		a := make([]interface{}, rv.Len())
+		for i := range a {
+			a[i] = r.value(rv.Index(i).Interface(), depth+1, masked)
+		}
+		return a
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return val
+		}
+		var hit bool
+		if v := r.value(rv.Elem().Interface(), depth+1, &hit); hit {
+			*masked = true
+			return v
+		}
+		return val
+	case reflect.Struct:
+		return r.structValue(rv, depth, masked)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return val
+		}
+	default:
+		return val
+	}
+	m := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		key := k.String()
+		if r.MatchKey(key) {
+			m[key], *masked = r.mask, true
+		} else {
+			m[key] = r.value(rv.MapIndex(k).Interface(), depth+1, masked)
+		}
+	}
+	return m
+}
+
+// structValue returns a map of the exported fields of rv if anything
+// is masked, or rv itself
+func (r *Redactor) structValue(rv reflect.Value, depth int, masked *bool) interface{} {
+	var hit bool
+	t := rv.Type()
+	m := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if r.MatchKey(f.Name) || tag != "" && tag != "-" && r.MatchKey(tag) {
+			m[f.Name], hit = r.mask, true
+		} else {
+			m[f.Name] = r.value(rv.Field(i).Interface(), depth+1, &hit)
+		}
+	}
+	if !hit {
+		return rv.Interface()
+	}
+	*masked = true
+	return m
+}
+
+// message is Message with masked set if anything is masked
+func (r *Redactor) message(s string, masked *bool) string {
+	msg := r.Message(s)
+	if msg != s {
+		*masked = true
+	}
+	return msg
+}
+
+// redactEntry masks the message, fields and error messages of ent
+func (r *Redactor) redactEntry(ent *entry) {
+	if r == nil {
+		return
+	}
+	ent.Message = r.Message(ent.Message)
+	ent.Fields = r.Fields(ent.Fields)
+	if ent.Err != nil {
+		r.redactError(ent.Err, 0)
+	}
+}
+
+func (r *Redactor) redactError(ei *errorInfo, depth int) {
+	ei.Message = r.Message(ei.Message)
+	if depth < maxErrorDepth {
+		for _, c := range ei.Causes {
+			r.redactError(c, depth+1)
+		}
+	}
+}
+
+// redactArgs masks the message formatted from args by fmt.Sprint
+func redactArgs(args []interface{}) []interface{} {
+	if r := GetRedactor(); r != nil {
+		return []interface{}{r.Message(fmt.Sprint(args...))}
+	}
+	return args
+}
+
+// redactf masks the message formatted from format and args by
+// fmt.Sprintf
+func redactf(format string, args []interface{}) (string, []interface{}) {
+	if r := GetRedactor(); r != nil {
+		return "%s", []interface{}{r.Message(fmt.Sprintf(format, args...))}
+	}
+	return format, args
+}
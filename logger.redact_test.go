@@ -0,0 +1,180 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestRedactor(t *testing.T) *Redactor {
+	r, err := NewRedactor(DefaultRedactKeys, `(?i)password=(\S+)`, `sk-[0-9a-z]{8,}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// redactURL is a fmt.Stringer
+type redactURL string
+
+func (u redactURL) String() string { return string(u) }
+
+func TestRedactor(t *testing.T) {
+	r := newTestRedactor(t)
+
+	for _, c := range []struct{ in, want string }{
+		{"login with password=abc123 ok", "login with password=*** ok"},
+		{"key sk-0123456789ab used", "key *** used"},
+		{"PASSWORD=x and password=y", "PASSWORD=*** and password=***"},
+		{"nothing here", "nothing here"},
+	} {
+		if got := r.Message(c.in); got != c.want {
+			t.Errorf("Message(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+
+	for _, key := range []string{"password", "Authorization", "access_token", "X-Token-Id"} {
+		if !r.MatchKey(key) {
+			t.Errorf("%q should be masked", key)
+		}
+	}
+	if r.MatchKey("user") {
+		t.Error("user should not be masked")
+	}
+
+	h := http.Header{"Authorization": {"Bearer x"}, "Accept": {"*/*"}}
+	m := r.Field("headers", h).(map[string]interface{})
+	if m["Authorization"] != DefaultRedactMask || fmt.Sprint(m["Accept"]) != "[*/*]" {
+		t.Errorf("bad nested redaction: %v", m)
+	}
+
+	m = r.Field("request", map[string]interface{}{
+		"args": []string{"password=abc", "-v"},
+		"url":  redactURL("https://x/?password=abc"),
+	}).(map[string]interface{})
+	if fmt.Sprint(m["args"]) != "[password=*** -v]" || m["url"] != "https://x/?password=***" {
+		t.Errorf("bad redaction of the slices and Stringers: %v", m)
+	}
+	if u := redactURL("https://x/"); r.Field("url", u) != u {
+		t.Error("a Stringer should be kept if nothing is masked")
+	}
+
+	var nilR *Redactor
+	if nilR.Message("password=x") != "password=x" || nilR.Field("password", 1) != 1 {
+		t.Error("a nil Redactor should redact nothing")
+	}
+
+	if _, err := NewRedactor(nil, "("); err == nil {
+		t.Error("expect an error for a bad pattern")
+	}
+}
+
+type redactCreds struct {
+	User     string
+	Password string
+	Key      string `json:"api_key"`
+	Query    url.Values
+	note     string
+}
+
+func TestRedactor_structs(t *testing.T) {
+	r := newTestRedactor(t)
+
+	c := &redactCreds{User: "u", Password: "p", Key: "k", Query: url.Values{"token": {"t"}, "q": {"x"}}, note: "n"}
+	m, ok := r.Field("creds", c).(map[string]interface{})
+	if !ok {
+		t.Fatalf("a struct with the sensitive fields should be redacted: %v", r.Field("creds", c))
+	}
+	if m["User"] != "u" || m["Password"] != DefaultRedactMask || m["Key"] != DefaultRedactMask {
+		t.Errorf("bad struct redaction: %v", m)
+	}
+	if q := m["Query"].(map[string]interface{}); q["token"] != DefaultRedactMask || fmt.Sprint(q["q"]) != "[x]" {
+		t.Errorf("bad nested redaction: %v", q)
+	}
+	if _, ok := m["note"]; ok {
+		t.Errorf("the unexported fields should be dropped: %v", m)
+	}
+	if c.Password != "p" || c.Query.Get("token") != "t" {
+		t.Error("the original value should not be changed")
+	}
+
+	req, _ := http.NewRequest("GET", "https://x/?password=abc", nil)
+	req.Header.Set("Authorization", "Bearer x")
+	m = r.Field("req", req).(map[string]interface{})
+	if m["Header"].(map[string]interface{})["Authorization"] != DefaultRedactMask || m["URL"] != "https://x/?password=***" {
+		t.Errorf("bad *http.Request redaction: %v", m)
+	}
+
+	type plain struct{ Name string }
+	if p := (&plain{"x"}); r.Field("v", p) != p || r.Field("v", plain{"x"}) != (plain{"x"}) {
+		t.Error("a struct should be kept if nothing is masked")
+	}
+	var nilCreds *redactCreds
+	if r.Field("v", nilCreds) != nilCreds {
+		t.Error("a nil pointer should be kept")
+	}
+}
+
+func TestRedactor_std(t *testing.T) {
+	requireOutput(t)
+	SetRedactor(newTestRedactor(t))
+	defer SetRedactor(nil)
+
+	l := NewStdLogger()
+	out := captureStdOutput(func() {
+		l.WithFields(map[string]interface{}{"password": "p4ss", "user": "bob"}).Infof("connect with password=%s", "p4ss")
+	})
+	if strings.Contains(out, "p4ss") || !strings.Contains(out, "password=***") || !strings.Contains(out, "user=bob") {
+		t.Fatalf("bad text output: %q", out)
+	}
+
+	l = NewStdLoggerWithConfig(NewLoggerConfig(func(lc *LoggerConfig) { lc.Format = "json" }))
+	out = captureStdOutput(func() {
+		l.With("token", "t0k").(EL).WithError(fmt.Errorf("bad password=p4ss")).Errorf("failed")
+	})
+	var m struct {
+		Token string `json:"token"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatal(err, out)
+	}
+	if m.Token != DefaultRedactMask || m.Error.Message != "bad password=***" {
+		t.Fatalf("bad json output: %q", out)
+	}
+}
+
+// recordSL records the messages written to a SystemdLogger
+type recordSL struct{ msgs []string }
+
+func (r *recordSL) rec(v ...interface{}) error { r.msgs = append(r.msgs, fmt.Sprint(v...)); return nil }
+func (r *recordSL) recf(format string, a ...interface{}) error {
+	r.msgs = append(r.msgs, fmt.Sprintf(format, a...))
+	return nil
+}
+
+func (r *recordSL) Error(v ...interface{}) error                   { return r.rec(v...) }
+func (r *recordSL) Warning(v ...interface{}) error                 { return r.rec(v...) }
+func (r *recordSL) Info(v ...interface{}) error                    { return r.rec(v...) }
+func (r *recordSL) Errorf(format string, a ...interface{}) error   { return r.recf(format, a...) }
+func (r *recordSL) Warningf(format string, a ...interface{}) error { return r.recf(format, a...) }
+func (r *recordSL) Infof(format string, a ...interface{}) error    { return r.recf(format, a...) }
+
+func TestRedactor_systemd(t *testing.T) {
+	requireOutput(t)
+	SetRedactor(newTestRedactor(t))
+	defer SetRedactor(nil)
+
+	sl := &recordSL{}
+	l := FromSystemdLogger(sl)
+	l.Infof("password=%s", "p4ss")
+	AsL(l).Warn("use ", "sk-0123456789ab")
+	if strings.Join(sl.msgs, "|") != "password=***|use ***" {
+		t.Fatalf("bad systemd messages: %q", sl.msgs)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright © 2023 Hedzr Yeh.
+
+package log
+
+import (
+	"github.com/hedzr/log/states"
+)
+
+// LevelFromStates derives a logging level from the counters and the
+// mode flags of states.Env(), which are set by the command-line flags
+// of a typical CLI app:
+//
+//	(none)  info
+//	-v      debug
+//	-vv     trace
+//	-q      warn
+//	-qq     error
+//
+// The counts of -v and -q offset each other, so -vq is info. A
+// verbose or quiet mode without a count is taken as one hit. The debug
+// and trace modes (or levels), such as `--debug` and `--trace`, raise
+// the result to debug and trace at least.
+func LevelFromStates() Level {
+	e := states.Env()
+	v, q := e.CountOfVerbose(), e.CountOfQuiet()
+	if v == 0 && e.IsVerboseModePure() {
+		v = 1
+	}
+	if q == 0 && e.IsQuietMode() {
+		q = 1
+	}
+	return levelFromStates(e.GetDebugMode() || e.GetDebugLevel() > 0, e.GetTraceMode() || e.GetTraceLevel() > 0, v, q)
+}
+
+func levelFromStates(debug, trace bool, verbose, quiet int) Level {
+	ladder := []Level{ErrorLevel, WarnLevel, InfoLevel, DebugLevel, TraceLevel}
+	i := 2 + verbose - quiet
+	if i < 0 {
+		i = 0
+	} else if i >= len(ladder) {
+		i = len(ladder) - 1
+	}
+	l := ladder[i]
+	if trace {
+		l = TraceLevel
+	} else if debug && !l.Allows(DebugLevel) {
+		l = DebugLevel
+	}
+	return l
+}
+
+// SyncLevelWithStates sets the level of the package-level logger by
+// LevelFromStates, and sets it again whenever the debug, trace,
+// verbose or quiet states are changed. The returned function stops the
+// syncing:
+//
+//	stop := log.SyncLevelWithStates()
+//	defer stop()
+func SyncLevelWithStates() (stop func()) {
+	apply := func() { SetLevel(LevelFromStates()) }
+	stop = states.OnChanged(apply)
+	apply()
+	return
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hedzr/log/states"
+)
+
+func TestLevelFromStates(t *testing.T) {
+	for _, x := range []struct {
+		debug, trace   bool
+		verbose, quiet int
+		want           Level
+	}{
+		{false, false, 0, 0, InfoLevel},
+		{false, false, 1, 0, DebugLevel},
+		{false, false, 2, 0, TraceLevel},
+		{false, false, 5, 0, TraceLevel},
+		{false, false, 0, 1, WarnLevel},
+		{false, false, 0, 2, ErrorLevel},
+		{false, false, 0, 5, ErrorLevel},
+		{false, false, 1, 1, InfoLevel},
+		{true, false, 0, 2, DebugLevel},
+		{true, false, 2, 0, TraceLevel},
+		{false, true, 0, 2, TraceLevel},
+	} {
+		if l := levelFromStates(x.debug, x.trace, x.verbose, x.quiet); l != x.want {
+			t.Errorf("%+v: got %v", x, l)
+		}
+	}
+}
+
+func TestSyncLevelWithStates(t *testing.T) {
+	e := states.Env()
+	saved := GetLevel()
+	dm, tm := e.GetDebugMode(), e.GetTraceMode()
+	defer func() {
+		e.SetVerboseCount(0)
+		e.SetQuietCount(0)
+		e.SetQuietMode(false)
+		e.SetDebugMode(dm)
+		e.SetTraceMode(tm)
+		SetLevel(saved)
+	}()
+	e.SetDebugMode(false)
+	e.SetTraceMode(false)
+	if e.GetDebugMode() || e.GetTraceMode() {
+		t.Skip("the debug or trace mode is forced, such as by the delve build tag")
+	}
+
+	stop := SyncLevelWithStates()
+	if l := GetLevel(); l != InfoLevel {
+		t.Fatalf("expect info, got %v", l)
+	}
+	e.SetVerboseCount(2)
+	if l := GetLevel(); l != TraceLevel {
+		t.Fatalf("-vv: expect trace, got %v", l)
+	}
+	e.SetVerboseCount(0)
+	e.SetQuietMode(true)
+	if l := GetLevel(); l != WarnLevel {
+		t.Fatalf("-q: expect warn, got %v", l)
+	}
+
+	stop()
+	e.SetQuietCount(2)
+	if l := GetLevel(); l != WarnLevel {
+		t.Fatalf("the level should not be synced after stop, got %v", l)
+	}
+	if l := LevelFromStates(); l != ErrorLevel {
+		t.Fatalf("-qq: expect error, got %v", l)
+	}
+}
+
+func TestSyncLevelWithStates_quiet(t *testing.T) {
+	requireOutput(t)
+	e := states.Env()
+	saved := GetLogger()
+	savedLevel := saved.GetLevel()
+	defer func() {
+		e.SetQuietCount(0)
+		SetLogger(saved)
+		SetLevel(savedLevel)
+	}()
+	if e.GetDebugMode() || e.GetTraceMode() {
+		t.Skip("the debug or trace mode is forced, such as by the delve build tag")
+	}
+	SetLogger(newStdLogger())
+
+	e.SetQuietCount(2)
+	stop := SyncLevelWithStates()
+	defer stop()
+	out := captureStdOutput(func() {
+		Infof("info")
+		Warnf("warn")
+		Errorf("error")
+	})
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 1 || !strings.HasSuffix(lines[0], "error") {
+		t.Fatalf("-qq: expect the errors only, got %q", out)
+	}
+}
@@ -1,136 +1,176 @@
-//go:build !verbose
-// +build !verbose
+//go:build !verbose && !noverbose
+// +build !verbose,!noverbose
 
 package log
 
-// VerboseEnabled identify whether `--tags=verbose` has been defined in go building
+import "github.com/hedzr/log/states"
+
+// VerboseEnabled identify whether `--tags=verbose` has been defined in go building.
+//
+// Without `--tags=verbose`, the V* functions still print while the
+// verbose mode is on at runtime, such as `--verbose` of a cmdr app.
+// Build with `--tags=noverbose` to discard them at compile time.
 const VerboseEnabled = false
 
-// VTracef prints the text to stdin if logging level is greater than TraceLevel.
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// VTracef prints the text to stdin if logging level is greater than TraceLevel
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VTracef(msg string, args ...interface{}) {
-	// logger.Tracef(msg, args...)
+	if states.Env().IsVerboseMode() {
+		GetLogger().Tracef(msg, args...)
+	}
 }
 
 // VDebugf prints the text to stdin if logging level is greater than DebugLevel
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VDebugf(msg string, args ...interface{}) {
-	// logger.Debugf(msg, args...)
+	if states.Env().IsVerboseMode() {
+		GetLogger().Debugf(msg, args...)
+	}
 }
 
 // VInfof prints the text to stdin if logging level is greater than InfoLevel
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VInfof(msg string, args ...interface{}) {
-	// logger.Infof(msg, args...)
+	if states.Env().IsVerboseMode() {
+		GetLogger().Infof(msg, args...)
+	}
 }
 
 // VWarnf prints the text to stderr
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VWarnf(msg string, args ...interface{}) {
-	// logger.Warnf(msg, args...)
+	if states.Env().IsVerboseMode() {
+		GetLogger().Warnf(msg, args...)
+	}
 }
 
 // VErrorf prints the text to stderr
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VErrorf(msg string, args ...interface{}) {
-	// logger.Errorf(msg, args...)
+	if states.Env().IsVerboseMode() {
+		GetLogger().Errorf(msg, args...)
+	}
 }
 
 // VFatalf is equivalent to Printf() followed by a call to os.Exit(1).
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VFatalf(msg string, args ...interface{}) {
-	// if InTesting() {
-	//	logger.Panicf(msg, args)
-	// }
-	// logger.Fatalf(msg, args...)
+	if states.Env().IsVerboseMode() {
+		if InTesting() && !hasExitFunc() {
+			GetLogger().Panicf(msg, args...)
+		}
+		GetLogger().Fatalf(msg, args...)
+	}
 }
 
 // VPanicf is equivalent to Printf() followed by a call to panic().
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VPanicf(msg string, args ...interface{}) {
-	// logger.Panicf(msg, args...)
+	if states.Env().IsVerboseMode() {
+		GetLogger().Panicf(msg, args...)
+	}
 }
 
 // VPrintf calls Output to print to the standard logger.
 // Arguments are handled in the manner of fmt.Printf.
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VPrintf(msg string, args ...interface{}) {
-	// logger.Printf(msg, args...)
+	if states.Env().IsVerboseMode() {
+		GetLogger().Printf(msg, args...)
+	}
 }
 
 // VTrace prints all args to stdin if logging level is greater than TraceLevel
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VTrace(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Trace(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			l.Trace(args...)
+		}
+	}
 }
 
 // VDebug prints all args to stdin if logging level is greater than DebugLevel
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VDebug(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Debug(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			l.Debug(args...)
+		}
+	}
 }
 
 // VInfo prints all args to stdin if logging level is greater than InfoLevel
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VInfo(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Info(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			l.Info(args...)
+		}
+	}
 }
 
 // VWarn prints all args to stderr
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VWarn(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Warn(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			l.Warn(args...)
+		}
+	}
 }
 
 // VError prints all args to stderr
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VError(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Error(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			l.Error(args...)
+		}
+	}
 }
 
 // VFatal is equivalent to Printf() followed by a call to os.Exit(1).
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VFatal(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	if InTesting() {
-	//		l.Panic(args)
-	//	}
-	//	l.Fatal(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			if InTesting() && !hasExitFunc() {
+				l.Panic(args...)
+			}
+			l.Fatal(args...)
+		}
+	}
 }
 
 // VPanic is equivalent to Printf() followed by a call to panic().
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VPanic(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Panic(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			l.Panic(args...)
+		}
+	}
 }
 
 // VPrint calls Output to print to the standard logger.
 // Arguments are handled in the manner of fmt.Print.
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VPrint(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Print(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			l.Print(args...)
+		}
+	}
 }
 
 // VPrintln calls Output to print to the standard logger.
 // Arguments are handled in the manner of fmt.Println.
-// It would be optimized to discard except `--tags=verbose` was been defined.
+// It's discarded unless the verbose mode is on, see states.Env().IsVerboseMode().
 func VPrintln(args ...interface{}) {
-	// if l := AsL(logger); l != nil {
-	//	l.Println(args...)
-	// }
+	if states.Env().IsVerboseMode() {
+		if l := AsL(GetLogger()); l != nil {
+			l.Println(args...)
+		}
+	}
 }
@@ -0,0 +1,41 @@
+//go:build !verbose && !noverbose
+// +build !verbose,!noverbose
+
+package log
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hedzr/log/states"
+)
+
+func TestVerboseFuncs_runtime(t *testing.T) {
+	requireOutput(t)
+	e := states.Env()
+	defer e.SetVerboseMode(e.IsVerboseModePure())
+
+	e.SetVerboseMode(false)
+	if out := captureStdOutput(func() { VWarnf("hidden %d", 1) }); strings.Contains(out, "hidden") {
+		t.Fatalf("expect nothing while the verbose mode is off: %q", out)
+	}
+
+	e.SetVerboseMode(true)
+	out := captureStdOutput(func() {
+		VWarnf("shown %d", 2)
+		VPrintln("shown", 3)
+	})
+	for _, s := range []string{"shown 2", "shown 3"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expect %q while the verbose mode is on: %q", s, out)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "fatal 4") {
+			t.Fatalf("VFatalf should panic with the formatted message in testing, got %v", r)
+		}
+	}()
+	_ = captureStdOutput(func() { VFatalf("fatal %d", 4) })
+}
@@ -9,59 +9,59 @@ const VerboseEnabled = true
 // VTracef prints the text to stdin if logging level is greater than TraceLevel
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VTracef(msg string, args ...interface{}) {
-	logger.Tracef(msg, args...)
+	GetLogger().Tracef(msg, args...)
 }
 
 // VDebugf prints the text to stdin if logging level is greater than DebugLevel
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VDebugf(msg string, args ...interface{}) {
-	logger.Debugf(msg, args...)
+	GetLogger().Debugf(msg, args...)
 }
 
 // VInfof prints the text to stdin if logging level is greater than InfoLevel
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VInfof(msg string, args ...interface{}) {
-	logger.Infof(msg, args...)
+	GetLogger().Infof(msg, args...)
 }
 
 // VWarnf prints the text to stderr
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VWarnf(msg string, args ...interface{}) {
-	logger.Warnf(msg, args...)
+	GetLogger().Warnf(msg, args...)
 }
 
 // VErrorf prints the text to stderr
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VErrorf(msg string, args ...interface{}) {
-	logger.Errorf(msg, args...)
+	GetLogger().Errorf(msg, args...)
 }
 
 // VFatalf is equivalent to Printf() followed by a call to os.Exit(1).
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VFatalf(msg string, args ...interface{}) {
-	if InTesting() {
-		logger.Panicf(msg, args)
+	if InTesting() && !hasExitFunc() {
+		GetLogger().Panicf(msg, args...)
 	}
-	logger.Fatalf(msg, args...)
+	GetLogger().Fatalf(msg, args...)
 }
 
 // VPanicf is equivalent to Printf() followed by a call to panic().
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VPanicf(msg string, args ...interface{}) {
-	logger.Panicf(msg, args...)
+	GetLogger().Panicf(msg, args...)
 }
 
 // VPrintf calls Output to print to the standard logger.
 // Arguments are handled in the manner of fmt.Printf.
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VPrintf(msg string, args ...interface{}) {
-	logger.Printf(msg, args...)
+	GetLogger().Printf(msg, args...)
 }
 
 // VTrace prints all args to stdin if logging level is greater than TraceLevel
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VTrace(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Trace(args...)
 	}
 }
@@ -69,7 +69,7 @@ func VTrace(args ...interface{}) {
 // VDebug prints all args to stdin if logging level is greater than DebugLevel
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VDebug(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Debug(args...)
 	}
 }
@@ -77,7 +77,7 @@ func VDebug(args ...interface{}) {
 // VInfo prints all args to stdin if logging level is greater than InfoLevel
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VInfo(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Info(args...)
 	}
 }
@@ -85,7 +85,7 @@ func VInfo(args ...interface{}) {
 // VWarn prints all args to stderr
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VWarn(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Warn(args...)
 	}
 }
@@ -93,7 +93,7 @@ func VWarn(args ...interface{}) {
 // VError prints all args to stderr
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VError(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Error(args...)
 	}
 }
@@ -101,9 +101,9 @@ func VError(args ...interface{}) {
 // VFatal is equivalent to Printf() followed by a call to os.Exit(1).
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VFatal(args ...interface{}) {
-	if l := AsL(logger); l != nil {
-		if InTesting() {
-			l.Panic(args)
+	if l := AsL(GetLogger()); l != nil {
+		if InTesting() && !hasExitFunc() {
+			l.Panic(args...)
 		}
 		l.Fatal(args...)
 	}
@@ -112,7 +112,7 @@ func VFatal(args ...interface{}) {
 // VPanic is equivalent to Printf() followed by a call to panic().
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VPanic(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Panic(args...)
 	}
 }
@@ -121,7 +121,7 @@ func VPanic(args ...interface{}) {
 // Arguments are handled in the manner of fmt.Print.
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VPrint(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Print(args...)
 	}
 }
@@ -130,7 +130,7 @@ func VPrint(args ...interface{}) {
 // Arguments are handled in the manner of fmt.Println.
 // It would be optimized to discard except `--tags=verbose` was been defined.
 func VPrintln(args ...interface{}) {
-	if l := AsL(logger); l != nil {
+	if l := AsL(GetLogger()); l != nil {
 		l.Println(args...)
 	}
 }
@@ -3,9 +3,12 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 //
@@ -24,29 +27,26 @@ func (level Level) String() string {
 }
 
 // ParseLevel takes a string level and returns the Logrus log level constant.
+//
+// The names and aliases of the built-in levels, and the names of the
+// levels registered by RegisterLevel, are case-insensitive. The
+// numeric form of a known level, such as "3" for WarnLevel, is
+// accepted too. ParseLevel has no side effects, an unknown level is
+// reported by the returned error only.
 func ParseLevel(lvl string) (Level, error) {
-	switch strings.ToLower(lvl) {
-	case "panic":
-		return PanicLevel, nil
-	case "fatal":
-		return FatalLevel, nil
-	case "error":
-		return ErrorLevel, nil
-	case "warn", "warning":
-		return WarnLevel, nil
-	case "info":
-		return InfoLevel, nil
-	case "debug", "devel", "dev", "develop":
-		return DebugLevel, nil
-	case "trace":
-		return TraceLevel, nil
-	case "off", "no", "disable":
-		return OffLevel, nil
-	}
-
-	log.Printf("unknown logging level string %q", lvl)
-
-	var l Level
+	levelsLock.RLock()
+	l, ok := levelNames[strings.ToLower(strings.TrimSpace(lvl))]
+	levelsLock.RUnlock()
+	if ok {
+		return l, nil
+	}
+
+	if n, err := strconv.ParseUint(strings.TrimSpace(lvl), 10, 32); err == nil {
+		if _, ok = levelInfoOf(Level(n)); ok {
+			return Level(n), nil
+		}
+	}
+
 	return l, fmt.Errorf("not a valid logging Level: %q", lvl)
 }
 
@@ -62,6 +62,75 @@ func (level *Level) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// Set implements flag.Value, so a Level can be a command-line flag:
+//
+//	lvl := log.InfoLevel
+//	flag.Var(&lvl, "log-level", "the logging level")
+func (level *Level) Set(s string) error { return level.UnmarshalText([]byte(s)) }
+
+// MarshalJSON implements json.Marshaler, a Level is encoded as its
+// name
+func (level Level) MarshalJSON() ([]byte, error) {
+	b, err := level.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it accepts a name or a
+// number. A null keeps level unchanged.
+func (level *Level) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		s = string(b) // a number
+	}
+	return level.UnmarshalText([]byte(s))
+}
+
+// MarshalYAML implements yaml.Marshaler, a Level is encoded as its
+// name
+func (level Level) MarshalYAML() (interface{}, error) {
+	b, err := level.MarshalText()
+	return string(b), err
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, it accepts a name or a
+// number
+func (level *Level) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return level.UnmarshalText([]byte(s))
+}
+
+// SyslogPriority returns the syslog priority (severity) of level, from
+// 0 (LOG_EMERG) to 7 (LOG_DEBUG)
+func (level Level) SyslogPriority() int {
+	li, ok := levelInfoOf(level)
+	if !ok {
+		return 7
+	}
+	return li.Syslog
+}
+
+// OTelSeverity returns the severity number of OpenTelemetry logs data
+// model, from 1 (TRACE) to 24 (FATAL4). It is 0 (UNSPECIFIED) for
+// OffLevel and an unknown level.
+func (level Level) OTelSeverity() int {
+	if li, ok := levelInfoOf(level); ok {
+		if li.OTel == 0 && level != OffLevel {
+			li, _ = levelInfoOf(level.builtin())
+		}
+		return li.OTel
+	}
+	return 0
+}
+
 // Available level names are:
 // "disable"
 // "fatal"
@@ -72,34 +141,23 @@ func (level *Level) UnmarshalText(text []byte) error {
 
 // MarshalText convert Level to string and []byte
 func (level Level) MarshalText() ([]byte, error) {
-	switch level {
-	case OffLevel:
-		return []byte("off"), nil
-	case TraceLevel:
-		return []byte("trace"), nil
-	case DebugLevel:
-		return []byte("debug"), nil
-	case InfoLevel:
-		return []byte("info"), nil
-	case WarnLevel:
-		return []byte("warning"), nil
-	case ErrorLevel:
-		return []byte("error"), nil
-	case FatalLevel:
-		return []byte("fatal"), nil
-	case PanicLevel:
-		return []byte("panic"), nil
+	if li, ok := levelInfoOf(level); ok {
+		return []byte(li.Name), nil
 	}
 
 	return nil, fmt.Errorf("not a valid logrus level %d", level)
 }
 
-// AllLevels is a constant exposing all logging levels
+// AllLevels is a constant exposing the built-in logging levels. They
+// are ordered by severity, and OffLevel is the last one. Use Levels to
+// get the levels registered by RegisterLevel too.
 var AllLevels = []Level{
 	PanicLevel,
 	FatalLevel,
+	CriticalLevel,
 	ErrorLevel,
 	WarnLevel,
+	NoticeLevel,
 	InfoLevel,
 	DebugLevel,
 	TraceLevel,
@@ -129,4 +187,176 @@ const (
 	TraceLevel
 	// OffLevel level. The logger will be shutdown.
 	OffLevel
+
+	// NoticeLevel level. Normal but significant entries, such as the
+	// audit events. It's between WarnLevel and InfoLevel in severity.
+	NoticeLevel
+	// CriticalLevel level. Critical conditions, such as the security
+	// events. It's between FatalLevel and ErrorLevel in severity.
+	CriticalLevel
+)
+
+// LevelInfo describes a level in the level table
+type LevelInfo struct {
+	// Name is the canonical name used by String and MarshalText
+	Name string
+	// Aliases are the other names accepted by ParseLevel
+	Aliases []string
+	// Severity orders the levels, a smaller value is more severe. The
+	// built-in levels are PanicLevel 0, FatalLevel 10, CriticalLevel
+	// 15, ErrorLevel 20, WarnLevel 30, NoticeLevel 35, InfoLevel 40,
+	// DebugLevel 50 and TraceLevel 60.
+	Severity int
+	// Color is the ANSI SGR color code, such as 31 for red
+	Color int
+	// Syslog is the syslog priority, from 0 (emerg) to 7 (debug)
+	Syslog int
+	// OTel is the OpenTelemetry severity number, from 1 (TRACE) to 24
+	// (FATAL4). If it's zero for a custom level, the one of the closest
+	// built-in level is used.
+	OTel int
+}
+
+// RegisterLevel adds a custom level into the level table and returns
+// it. The name must not be used by another level. RegisterLevel should
+// be called at initializing time, for example:
+//
+//	var AuditLevel = log.MustRegisterLevel(log.LevelInfo{Name: "audit", Severity: 33, Color: 35, Syslog: 5})
+//
+// A custom level can be logged by Logf, it is gated by its severity.
+func RegisterLevel(li LevelInfo) (Level, error) {
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+
+	li.Name = strings.ToLower(li.Name)
+	for _, name := range append([]string{li.Name}, li.Aliases...) {
+		if _, ok := levelNames[strings.ToLower(name)]; ok || name == "" {
+			return 0, fmt.Errorf("logging level name %q is empty or used", name)
+		}
+	}
+	if li.Severity < 0 {
+		return 0, fmt.Errorf("logging level %q: the severity should not be negative", li.Name)
+	}
+
+	lvl := nextCustomLevel
+	nextCustomLevel++
+	addLevel(lvl, li)
+
+	all := append([]Level(nil), allLevels[:len(allLevels)-1]...)
+	all = append(all, lvl)
+	sort.SliceStable(all, func(i, j int) bool { return levels[all[i]].Severity < levels[all[j]].Severity })
+	allLevels = append(all, OffLevel)
+	return lvl, nil
+}
+
+// Levels returns all logging levels, including the levels registered
+// by RegisterLevel. They are ordered by severity, and OffLevel is the
+// last one. The returned slice is a copy, it's safe to be modified.
+func Levels() []Level {
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+	return append([]Level(nil), allLevels...)
+}
+
+// MustRegisterLevel is like RegisterLevel but panics on error
+func MustRegisterLevel(li LevelInfo) Level {
+	lvl, err := RegisterLevel(li)
+	if err != nil {
+		panic(err)
+	}
+	return lvl
+}
+
+// Info returns the description of level in the level table
+func (level Level) Info() (li LevelInfo, ok bool) { return levelInfoOf(level) }
+
+// Severity returns the severity of level, a smaller value is more
+// severe. It is -1 for OffLevel and an unknown level.
+func (level Level) Severity() int {
+	if li, ok := levelInfoOf(level); ok && level != OffLevel {
+		return li.Severity
+	}
+	return -1
+}
+
+// Color returns the ANSI SGR color code of level
+func (level Level) Color() int {
+	li, _ := levelInfoOf(level)
+	return li.Color
+}
+
+// Allows tests if an entry at lvl should be written while the logging
+// level is set to threshold. It compares the severities, so it works
+// for NoticeLevel, CriticalLevel and the custom levels. Nothing is
+// allowed by OffLevel.
+func (threshold Level) Allows(lvl Level) bool {
+	s := lvl.Severity()
+	return s >= 0 && s <= threshold.Severity()
+}
+
+// builtin returns the built-in level among PanicLevel..TraceLevel
+// which is the closest to level and not more severe than it, for the
+// loggers which don't support the other levels.
+func (level Level) builtin() Level {
+	if level <= TraceLevel {
+		return level
+	}
+	s := level.Severity()
+	for l := PanicLevel; l < TraceLevel; l++ {
+		if l.Severity() >= s {
+			return l
+		}
+	}
+	return TraceLevel
+}
+
+func levelInfoOf(level Level) (li LevelInfo, ok bool) {
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+	li, ok = levels[level]
+	return
+}
+
+func addLevel(lvl Level, li LevelInfo) {
+	levels[lvl] = li
+	levelNames[li.Name] = lvl
+	for _, a := range li.Aliases {
+		levelNames[strings.ToLower(a)] = lvl
+	}
+}
+
+// builtinLevels fills the level table, it's called at the variables
+// initializing so that ParseLevel works in any init()
+func builtinLevels() bool {
+	levels, levelNames = make(map[Level]LevelInfo), make(map[string]Level)
+	for _, x := range []struct {
+		lvl Level
+		li  LevelInfo
+	}{
+		{PanicLevel, LevelInfo{Name: "panic", Severity: 0, Color: 31, Syslog: 0, OTel: 24}},
+		{FatalLevel, LevelInfo{Name: "fatal", Severity: 10, Color: 31, Syslog: 1, OTel: 21}},
+		{CriticalLevel, LevelInfo{Name: "critical", Aliases: []string{"crit"}, Severity: 15, Color: 31, Syslog: 2, OTel: 20}},
+		{ErrorLevel, LevelInfo{Name: "error", Aliases: []string{"err"}, Severity: 20, Color: 31, Syslog: 3, OTel: 17}},
+		{WarnLevel, LevelInfo{Name: "warning", Aliases: []string{"warn"}, Severity: 30, Color: 33, Syslog: 4, OTel: 13}},
+		{NoticeLevel, LevelInfo{Name: "notice", Severity: 35, Color: 32, Syslog: 5, OTel: 10}},
+		{InfoLevel, LevelInfo{Name: "info", Severity: 40, Color: 36, Syslog: 6, OTel: 9}},
+		{DebugLevel, LevelInfo{Name: "debug", Aliases: []string{"devel", "dev", "develop"}, Severity: 50, Color: 37, Syslog: 7, OTel: 5}},
+		{TraceLevel, LevelInfo{Name: "trace", Severity: 60, Color: 37, Syslog: 7, OTel: 1}},
+		{OffLevel, LevelInfo{Name: "off", Aliases: []string{"no", "disable"}, Severity: -1, Syslog: 7}},
+	} {
+		addLevel(x.lvl, x.li)
+	}
+	return true
+}
+
+// firstCustomLevel leaves some room for the future built-in levels
+const firstCustomLevel Level = 32
+
+var (
+	levelsLock      sync.RWMutex
+	levels          map[Level]LevelInfo
+	levelNames      map[string]Level
+	allLevels       = append([]Level(nil), AllLevels...)
+	_               = builtinLevels()
+	nextCustomLevel = firstCustomLevel
 )
@@ -3,10 +3,15 @@
 package log
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/hedzr/log/states"
+	"gopkg.in/yaml.v2"
 )
 
 func TestLevels(t *testing.T) {
@@ -135,3 +140,170 @@ func tttp1() {
 
 	Panicf("panic")
 }
+
+func TestLevels_table(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		want Level
+	}{
+		{"notice", NoticeLevel}, {"CRITICAL", CriticalLevel}, {"crit", CriticalLevel},
+		{"warn", WarnLevel}, {"err", ErrorLevel}, {"disable", OffLevel},
+	} {
+		if l, err := ParseLevel(c.name); err != nil || l != c.want {
+			t.Fatalf("ParseLevel(%q) = %v, %v", c.name, l, err)
+		}
+	}
+
+	for i := 1; i < len(AllLevels)-1; i++ {
+		if AllLevels[i-1].Severity() >= AllLevels[i].Severity() {
+			t.Fatalf("AllLevels should be ordered by severity: %v", AllLevels)
+		}
+	}
+	if AllLevels[len(AllLevels)-1] != OffLevel {
+		t.Fatal("OffLevel should be the last one")
+	}
+
+	if WarnLevel.Allows(NoticeLevel) || !InfoLevel.Allows(NoticeLevel) || !ErrorLevel.Allows(CriticalLevel) || OffLevel.Allows(PanicLevel) {
+		t.Fatal("bad Allows")
+	}
+	if NoticeLevel.builtin() != InfoLevel || CriticalLevel.builtin() != ErrorLevel || WarnLevel.builtin() != WarnLevel {
+		t.Fatal("bad builtin mapping")
+	}
+	if li, ok := CriticalLevel.Info(); !ok || li.Syslog != 2 || CriticalLevel.Color() != 31 {
+		t.Fatalf("bad level info: %+v", li)
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	audit, err := RegisterLevel(LevelInfo{Name: "Audit", Severity: 33, Color: 35, Syslog: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.String() != "audit" {
+		t.Fatalf("bad name %q", audit.String())
+	}
+	if l, err := ParseLevel("AUDIT"); err != nil || l != audit {
+		t.Fatalf("ParseLevel(AUDIT) = %v, %v", l, err)
+	}
+	if b, _ := audit.MarshalText(); string(b) != "audit" {
+		t.Fatalf("bad MarshalText %q", b)
+	}
+	found, all := false, Levels()
+	for i, l := range all {
+		if l == audit {
+			found = all[i-1] == WarnLevel && all[i+1] == NoticeLevel
+		}
+	}
+	if !found || len(AllLevels) != 10 {
+		t.Fatalf("audit should be between warn and notice: %v, %v", all, AllLevels)
+	}
+	if audit.builtin() != InfoLevel || !InfoLevel.Allows(audit) || WarnLevel.Allows(audit) {
+		t.Fatal("audit should be gated by its severity")
+	}
+
+	if _, err = RegisterLevel(LevelInfo{Name: "audit", Severity: 1}); err == nil {
+		t.Fatal("a used name should be rejected")
+	}
+	if _, err = RegisterLevel(LevelInfo{Name: "x", Aliases: []string{"warn"}}); err == nil {
+		t.Fatal("a used alias should be rejected")
+	}
+
+	requireOutput(t)
+	out := captureStdOutput(func() {
+		Logf(audit, "user %s logged in", "bob")
+		Noticef("notice %d", 1)
+		Criticalf("critical %d", 2)
+		Logf(OffLevel, "never")
+	})
+	for _, s := range []string{"user bob logged in", "notice 1", "critical 2"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expect %q in %q", s, out)
+		}
+	}
+	if strings.Contains(out, "never") {
+		t.Fatalf("OffLevel should not be printed: %q", out)
+	}
+}
+
+func TestLevel_encoding(t *testing.T) {
+	for s, want := range map[string]Level{"3": WarnLevel, " info ": InfoLevel, "9": CriticalLevel, "Notice": NoticeLevel} {
+		if l, err := ParseLevel(s); err != nil || l != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", s, l, err, want)
+		}
+	}
+	for _, s := range []string{"20", "-1", "loud", ""} {
+		if _, err := ParseLevel(s); err == nil {
+			t.Errorf("ParseLevel(%q) should fail", s)
+		}
+	}
+
+	var lvl Level
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(&lvl, "log-level", "the logging level")
+	if err := fs.Parse([]string{"-log-level", "debug"}); err != nil || lvl != DebugLevel {
+		t.Fatalf("flag: %v, %v", lvl, err)
+	}
+	if err := fs.Parse([]string{"-log-level", "loud"}); err == nil {
+		t.Fatal("flag: expect an error for an unknown level")
+	}
+
+	var c struct {
+		Level Level `json:"level" yaml:"level"`
+	}
+	for _, s := range []string{`{"level":"warning"}`, `{"level":3}`, `{"level":"WARN"}`} {
+		c.Level = InfoLevel
+		if err := json.Unmarshal([]byte(s), &c); err != nil || c.Level != WarnLevel {
+			t.Errorf("json %s: %v, %v", s, c.Level, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"level":null}`), &c); err != nil || c.Level != WarnLevel {
+		t.Errorf("json null should keep the level: %v, %v", c.Level, err)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"loud"}`), &c); err == nil {
+		t.Error("json: expect an error for an unknown level")
+	}
+	c.Level = NoticeLevel
+	if b, err := json.Marshal(c); err != nil || string(b) != `{"level":"notice"}` {
+		t.Errorf("json.Marshal: %s, %v", b, err)
+	}
+	if _, err := json.Marshal(Level(99)); err == nil {
+		t.Error("json.Marshal: expect an error for an unknown level")
+	}
+
+	for _, s := range []string{"level: critical\n", "level: 9\n"} {
+		c.Level = InfoLevel
+		if err := yaml.Unmarshal([]byte(s), &c); err != nil || c.Level != CriticalLevel {
+			t.Errorf("yaml %q: %v, %v", s, c.Level, err)
+		}
+	}
+	if b, err := yaml.Marshal(c); err != nil || string(b) != "level: critical\n" {
+		t.Errorf("yaml.Marshal: %q, %v", b, err)
+	}
+}
+
+func TestLevel_severities(t *testing.T) {
+	for _, x := range []struct {
+		lvl          Level
+		syslog, otel int
+	}{
+		{PanicLevel, 0, 24},
+		{FatalLevel, 1, 21},
+		{CriticalLevel, 2, 20},
+		{ErrorLevel, 3, 17},
+		{WarnLevel, 4, 13},
+		{NoticeLevel, 5, 10},
+		{InfoLevel, 6, 9},
+		{DebugLevel, 7, 5},
+		{TraceLevel, 7, 1},
+		{OffLevel, 7, 0},
+		{Level(99), 7, 0},
+	} {
+		if p := x.lvl.SyslogPriority(); p != x.syslog {
+			t.Errorf("%v: syslog priority %d, want %d", x.lvl, p, x.syslog)
+		}
+		if n := x.lvl.OTelSeverity(); n != x.otel {
+			t.Errorf("%v: otel severity %d, want %d", x.lvl, n, x.otel)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright © 2023 Hedzr Yeh.
+
+// The printfcheck command checks the format strings passed to the
+// printf-style functions and methods of hedzr/log, and the templates
+// of timing.WithMsgFormat.
+//
+//	printfcheck ./...
+//	go vet -vettool=$(which printfcheck) ./...
+package main
+
+import (
+	"github.com/hedzr/log/printfcheck"
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() { multichecker.Main(printfcheck.Analyzers...) }
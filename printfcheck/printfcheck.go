@@ -0,0 +1,77 @@
+// Copyright © 2023 Hedzr Yeh.
+
+// Package printfcheck provides the printf checker of go vet, configured
+// to validate the format strings of the printf-style functions and
+// methods of hedzr/log.
+//
+// The printf checker of go vet only knows the functions from the
+// standard library and the wrappers forwarding to them, so it misses
+// such as log.Infof which forwards to a method of the package-level
+// Logger. This analyzer is the upstream printf pass
+// (golang.org/x/tools/go/analysis/passes/printf) with the Wrappers
+// added by its -funcs flag:
+//
+//   - the package-level functions of hedzr/log: Tracef, ..., Printf,
+//     VTracef, ..., VPrintf, Noticef, Criticalf and Logf;
+//   - the methods Tracef, ..., Printf of log.LF, which are inherited by
+//     log.Logger, and Logf of log.LL.
+//
+// The printf pass takes a name without a trailing 'f' as a print-style
+// one, so the others are left to be found by the pass itself, which
+// reports a function forwarding its format and arguments to a known
+// printf-style one. Such as log.ErrorfWith, and the formatting
+// functions of hedzr/log/color, like color.Error and color.ToColor.
+//
+// The printf pass cannot see the calls through a function value, nor
+// the template of timing.WithMsgFormat, which is formatted later with
+// the elapsed time. TimingAnalyzer checks those of hedzr/log/timing:
+// the calls through a timing.Writer, and the templates. The callbacks
+// of hedzr/log/exec, such as WithOnOK and WithOnError, take no format,
+// so there is nothing else to check.
+//
+// Analyzers are both of them, run them as a standalone tool or a vet
+// tool:
+//
+//	go install github.com/hedzr/log/printfcheck/cmd/printfcheck@latest
+//	printfcheck ./...
+//	go vet -vettool=$(which printfcheck) ./...
+package printfcheck
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/printf"
+)
+
+const logPkg = "github.com/hedzr/log"
+
+// Wrappers are the full names, as reported by types.Func.FullName, of
+// the printf-style functions and methods of hedzr/log which are named
+// with a trailing 'f'. The format is the parameter before the variadic
+// arguments.
+var Wrappers = []string{
+	logPkg + ".Tracef", logPkg + ".Debugf", logPkg + ".Infof", logPkg + ".Warnf",
+	logPkg + ".Errorf", logPkg + ".Fatalf", logPkg + ".Panicf", logPkg + ".Printf",
+	logPkg + ".VTracef", logPkg + ".VDebugf", logPkg + ".VInfof", logPkg + ".VWarnf",
+	logPkg + ".VErrorf", logPkg + ".VFatalf", logPkg + ".VPanicf", logPkg + ".VPrintf",
+	logPkg + ".Noticef", logPkg + ".Criticalf", logPkg + ".Logf",
+
+	"(" + logPkg + ".LF).Tracef", "(" + logPkg + ".LF).Debugf",
+	"(" + logPkg + ".LF).Infof", "(" + logPkg + ".LF).Warnf",
+	"(" + logPkg + ".LF).Errorf", "(" + logPkg + ".LF).Fatalf",
+	"(" + logPkg + ".LF).Panicf", "(" + logPkg + ".LF).Printf",
+	"(" + logPkg + ".LL).Logf",
+}
+
+// Analyzer is printf.Analyzer with the Wrappers added
+var Analyzer = printf.Analyzer
+
+// Analyzers are Analyzer and TimingAnalyzer
+var Analyzers = []*analysis.Analyzer{Analyzer, TimingAnalyzer}
+
+func init() {
+	if err := Analyzer.Flags.Set("funcs", strings.Join(Wrappers, ",")); err != nil {
+		panic(err)
+	}
+}
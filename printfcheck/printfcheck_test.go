@@ -0,0 +1,18 @@
+// Copyright © 2023 Hedzr Yeh.
+
+package printfcheck_test
+
+import (
+	"testing"
+
+	"github.com/hedzr/log/printfcheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), printfcheck.Analyzer, "a")
+}
+
+func TestTimingAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), printfcheck.TimingAnalyzer, "b")
+}
@@ -0,0 +1,52 @@
+package a
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hedzr/log"
+	"github.com/hedzr/log/color"
+)
+
+type stringer struct{}
+
+func (stringer) String() string { return "" }
+
+func funcs(args []interface{}) {
+	log.Infof("%d items in %s", 3, "box")
+	log.Infof("%d items", "three")            // want `github.com/hedzr/log.Infof format %d has arg "three" of wrong type string`
+	log.Errorf("%s and %s", "one")            // want `github.com/hedzr/log.Errorf format %s reads arg #2, but call has 1 arg`
+	log.Errorf("done", 1)                     // want `github.com/hedzr/log.Errorf call has arguments but no formatting directives`
+	log.VDebugf("%z", 1)                      // want `github.com/hedzr/log.VDebugf format %z has unknown verb z`
+	log.Infof("wrapped: %w", errors.New("x")) // want `github.com/hedzr/log.Infof does not support error-wrapping directive %w`
+	log.Infof("%t", 1)                        // want `github.com/hedzr/log.Infof format %t has arg 1 of wrong type int`
+	log.Infof("%s", stringer{})
+	log.Infof("%v %s", time.Second, time.Second)
+	log.Infof("%[1]d %[1]x", 12)
+	log.Infof("%*d", 3, 12)
+	log.Infof("100%% done")
+	log.Infof("%d %d", args...)
+	log.Infof("%5.2f%", 1.0) // want `github.com/hedzr/log.Infof format % is missing verb at end of string`
+	log.ErrorfWith(errors.New("x"), "%d", 1)
+	log.ErrorfWith(errors.New("x"), "%d") // want `github.com/hedzr/log.ErrorfWith format %d reads arg #1, but call has 0 args`
+	log.Info("%d")
+}
+
+func methods(l log.Logger) {
+	l.Infof("%s", 1)                 // want `\(github.com/hedzr/log.LF\).Infof format %s has arg 1 of wrong type int`
+	l.With("k", 1).Warnf("%d %d", 1) // want `\(github.com/hedzr/log.LF\).Warnf format %d reads arg #2, but call has 1 arg`
+	log.GetLogger().Infof("ok %v", l)
+}
+
+func colors() {
+	color.Error("%d", "x") // want `github.com/hedzr/log/color.Error format %d has arg "x" of wrong type string`
+	color.Text("%s", "x")
+	_ = color.ToColor(color.FgRed, "%s %s", "x") // want `github.com/hedzr/log/color.ToColor format %s reads arg #2, but call has 1 arg`
+}
+
+func levels(ll log.LL) {
+	log.Noticef("%d", "x")                  // want `github.com/hedzr/log.Noticef format %d has arg "x" of wrong type string`
+	log.Logf(log.NoticeLevel, "%s %s", "x") // want `github.com/hedzr/log.Logf format %s reads arg #2, but call has 1 arg`
+	ll.Logf(log.NoticeLevel, "%d", 1)
+	ll.Logf(log.NoticeLevel, "%d", "x") // want `\(github.com/hedzr/log.LL\).Logf format %d has arg "x" of wrong type string`
+}
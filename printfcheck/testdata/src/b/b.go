@@ -0,0 +1,28 @@
+package b
+
+import "github.com/hedzr/log/timing"
+
+const tmpl = "build took %v"
+
+func msgFormats(s string) {
+	timing.WithMsgFormat("took %v")
+	timing.WithMsgFormat("took %[1]v (%[1]d ns), 100%%")
+	timing.WithMsgFormat(tmpl)
+	timing.WithMsgFormat(s)
+	timing.WithMsgFormat("took")        // want `timing.WithMsgFormat format "took" has no directive for the elapsed time`
+	timing.WithMsgFormat("%s took %v")  // want `timing.WithMsgFormat format %v reads arg #2, but the elapsed time is the only arg`
+	timing.WithMsgFormat("took %.2f s") // want `timing.WithMsgFormat format %f has the elapsed time.Duration of wrong type`
+	timing.WithMsgFormat("took %v%")    // want `timing.WithMsgFormat format is missing verb at end of string`
+	timing.WithMsgFormat("took %[0]v")  // want `timing.WithMsgFormat format has an invalid argument index`
+}
+
+func writers(w timing.Writer, args []interface{}) {
+	w("%d items in %s", 3, "box")
+	w("%*d", 3, 12)
+	w("%d %d", args...)
+	w("%d items in %s", 3) // want `timing.Writer format %s reads arg #2, but call has 1 args`
+	w("done", 1)           // want `timing.Writer call has arguments but no formatting directives`
+	w("%d", 1, 2)          // want `timing.Writer call needs 1 args but has 2 args`
+	w("%z", 1)             // want `timing.Writer format %z has unknown verb z`
+	_ = timing.WithWriter(timing.Writer(w))
+}
@@ -0,0 +1,15 @@
+package color
+
+import "fmt"
+
+type Color int
+
+const FgRed Color = 31
+
+func Error(format string, args ...interface{}) { logTo(format, args...) }
+func Text(format string, args ...interface{})  { fmt.Printf(format, args...) }
+func ToColor(clr Color, format string, args ...interface{}) string {
+	return fmt.Sprintf(format, args...)
+}
+
+func logTo(format string, args ...interface{}) { println(fmt.Sprintf(format, args...)) }
@@ -0,0 +1,31 @@
+package log
+
+func Infof(msg string, args ...interface{})                 {}
+func Errorf(msg string, args ...interface{})                {}
+func VDebugf(msg string, args ...interface{})               {}
+func ErrorfWith(err error, msg string, args ...interface{}) { GetLogger().Errorf(msg, args...) }
+func Info(args ...interface{})                              {}
+
+type LF interface {
+	Infof(msg string, args ...interface{})
+	Warnf(msg string, args ...interface{})
+	Errorf(msg string, args ...interface{})
+}
+
+type Logger interface {
+	LF
+	With(key string, val interface{}) Logger
+}
+
+func GetLogger() Logger { return nil }
+
+type Level uint32
+
+const NoticeLevel Level = 8
+
+func Noticef(msg string, args ...interface{})         {}
+func Logf(lvl Level, msg string, args ...interface{}) {}
+
+type LL interface {
+	Logf(lvl Level, msg string, args ...interface{})
+}
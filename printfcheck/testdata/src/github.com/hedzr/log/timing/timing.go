@@ -0,0 +1,8 @@
+package timing
+
+type Opt func()
+
+type Writer func(msg string, args ...interface{})
+
+func WithMsgFormat(msgTemplate string) Opt { return nil }
+func WithWriter(w Writer) Opt              { return nil }
@@ -0,0 +1,211 @@
+// Copyright © 2023 Hedzr Yeh.
+
+package printfcheck
+
+import (
+	"errors"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const timingPkg = logPkg + "/timing"
+
+// TimingAnalyzer checks the format strings of hedzr/log/timing, which
+// the printf pass cannot see:
+//
+//   - the template of timing.WithMsgFormat, which is formatted with the
+//     elapsed time.Duration as the only argument;
+//   - the calls through a timing.Writer value, such as w("%d", n).
+//
+// A template or a format which is not a constant is not checked. The
+// arguments of a timing.Writer call are counted but not type-checked.
+var TimingAnalyzer = &analysis.Analyzer{
+	Name:     "timingformat",
+	Doc:      "check the format strings of timing.WithMsgFormat and the calls through a timing.Writer",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runTiming,
+}
+
+func runTiming(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func); ok && fn.FullName() == timingPkg+".WithMsgFormat" {
+			checkMsgFormat(pass, call)
+		} else if isWriter(pass.TypesInfo.Types[call.Fun]) {
+			checkWriterCall(pass, call)
+		}
+	})
+	return nil, nil
+}
+
+// durationVerbs are the verbs which accept a time.Duration
+const durationVerbs = "bcdoqsvxTUX"
+
+func checkMsgFormat(pass *analysis.Pass, call *ast.CallExpr) {
+	format, ok := constString(pass, call.Args[0])
+	if !ok {
+		return
+	}
+	ds, err := parseFormat(format)
+	if err != nil {
+		pass.Reportf(call.Args[0].Pos(), "timing.WithMsgFormat format %s", err)
+		return
+	}
+	read := false
+	for _, d := range ds {
+		for _, n := range d.args {
+			if n != 1 {
+				pass.Reportf(call.Args[0].Pos(), "timing.WithMsgFormat format %%%c reads arg #%d, but the elapsed time is the only arg", d.verb, n)
+				return
+			}
+			read = true
+		}
+		if d.verb != '%' && !strings.ContainsRune(durationVerbs, d.verb) {
+			pass.Reportf(call.Args[0].Pos(), "timing.WithMsgFormat format %%%c has the elapsed time.Duration of wrong type", d.verb)
+			return
+		}
+	}
+	if !read {
+		pass.Reportf(call.Args[0].Pos(), "timing.WithMsgFormat format %q has no directive for the elapsed time", format)
+	}
+}
+
+// formatVerbs are the verbs known by fmt.Sprintf
+const formatVerbs = "bcdeEfFgGopqstTUvxX%"
+
+func checkWriterCall(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return
+	}
+	format, ok := constString(pass, call.Args[0])
+	if !ok {
+		return
+	}
+	ds, err := parseFormat(format)
+	if err != nil {
+		pass.Reportf(call.Pos(), "timing.Writer format %s", err)
+		return
+	}
+	most, has := 0, len(call.Args)-1
+	for _, d := range ds {
+		if !strings.ContainsRune(formatVerbs, d.verb) {
+			pass.Reportf(call.Pos(), "timing.Writer format %%%c has unknown verb %c", d.verb, d.verb)
+			return
+		}
+		for _, n := range d.args {
+			if n > has {
+				pass.Reportf(call.Pos(), "timing.Writer format %%%c reads arg #%d, but call has %d args", d.verb, n, has)
+				return
+			}
+			if n > most {
+				most = n
+			}
+		}
+	}
+	if most == 0 && has > 0 {
+		pass.Reportf(call.Pos(), "timing.Writer call has arguments but no formatting directives")
+	} else if most < has {
+		pass.Reportf(call.Pos(), "timing.Writer call needs %d args but has %d args", most, has)
+	}
+}
+
+// isWriter tests if tv is a value of timing.Writer, and not the type
+// itself as in a conversion
+func isWriter(tv types.TypeAndValue) bool {
+	if tv.IsType() || tv.Type == nil {
+		return false
+	}
+	named, ok := tv.Type.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == timingPkg && obj.Name() == "Writer"
+}
+
+func constString(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	tv := pass.TypesInfo.Types[expr]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// directive is a formatting directive of a format string
+type directive struct {
+	verb rune
+	args []int // the 1-based indexes of the args read by '*' and the verb
+}
+
+// parseFormat scans the directives of format as fmt does. The flags,
+// widths and precisions are skipped, except that a '*' reads an arg.
+func parseFormat(format string) (ds []directive, err error) {
+	argNum := 1
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+		var d directive
+		for i++; i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0; i++ {
+		}
+		index := func() bool {
+			if i >= len(format) || format[i] != '[' {
+				return true
+			}
+			j := strings.IndexByte(format[i:], ']')
+			if j < 0 {
+				return false
+			}
+			n, e := strconv.Atoi(format[i+1 : i+j])
+			if e != nil || n < 1 {
+				return false
+			}
+			argNum, i = n, i+j+1
+			return true
+		}
+		number := func() {
+			if i < len(format) && format[i] == '*' {
+				d.args = append(d.args, argNum)
+				argNum++
+				i++
+				return
+			}
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+		ok := index()
+		number()
+		if ok && i < len(format) && format[i] == '.' {
+			i++
+			ok = index()
+			number()
+		}
+		if !ok || !index() {
+			return nil, errors.New("has an invalid argument index")
+		}
+		if i >= len(format) {
+			return nil, errors.New("is missing verb at end of string")
+		}
+		verb, w := utf8.DecodeRuneInString(format[i:])
+		i += w
+		d.verb = verb
+		if verb != '%' {
+			d.args = append(d.args, argNum)
+			argNum++
+		}
+		ds = append(ds, d)
+	}
+	return
+}
@@ -1,34 +1,40 @@
 package log
 
 import (
+	"fmt"
+	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
 )
 
-// CalcStackFrames _
+// CalcStackFrames returns how many frames should be skipped from the
+// caller of CalcStackFrames, so that the frames inside the known
+// logging packages (such as hedzr/log, logrus, ...) are ignored.
 func CalcStackFrames(skipFramesAtFirst int) (skipped int) {
+	skipped, _ = calcStackFrames(skipFramesAtFirst + 1)
+	return
+}
 
-	// cache this package's fully-qualified name
-	callerInitOnce.Do(func() {
-		pcs := make([]uintptr, 2)
-		_ = runtime.Callers(0, pcs)
-		logPackage := "github.com/hedzr/log" // and logx, logex
-		logrusPackage := "github.com/sirupsen/logrus"
-		errorsPackage := "gopkg.in/hedzr/errors.v"
-		knownPackages = append(knownPackages, "runtime", "reflect", logPackage, logrusPackage, errorsPackage)
-		knownPathes = []string{"/usr/local/go/src/"}
-	})
+// calcStackFrames walks the stack like CalcStackFrames and returns
+// the first frame outside the known packages as the caller too.
+//
+// A frame inside a _test.go file is never skipped, so the tests of
+// these known packages get the right caller.
+func calcStackFrames(skipFramesAtFirst int) (skipped int, caller runtime.Frame) {
+	callerInitOnce.Do(initKnownPackages)
 
 	// Restrict the lookback frames to avoid runaway lookups
 	pcs := make([]uintptr, maximumCallerDepth)
 	depth := runtime.Callers(skipFramesAtFirst+2 /*minimumCallerDepth*/ /*+skipFrames*/, pcs)
 	frames := runtime.CallersFrames(pcs[:depth])
 
-	skipped = 0
+	knownLock.RLock()
+	defer knownLock.RUnlock()
 	for f, again := frames.Next(); again; f, again = frames.Next() {
-		pkg := getPackageName(f.Function)
-		if !contains(knownPackages, pkg) && !containsPartialsOnly(knownPathes, f.File) {
+		if !isKnownFrame(&f) {
+			caller = f
 			break
 		}
 		skipped++
@@ -37,14 +43,91 @@ func CalcStackFrames(skipFramesAtFirst int) (skipped int) {
 	return
 }
 
+// captureStack returns the stack trace of the current goroutine from
+// the caller located by CalcStackFrames. The frames inside the known
+// packages and the standard library are filtered out.
+func captureStack(skipFramesAtFirst int) (stack []string) {
+	skipped, _ := calcStackFrames(skipFramesAtFirst + 1)
+
+	pcs := make([]uintptr, maximumStackDepth)
+	depth := runtime.Callers(skipFramesAtFirst+2+skipped, pcs)
+	frames := runtime.CallersFrames(pcs[:depth])
+
+	knownLock.RLock()
+	defer knownLock.RUnlock()
+	for {
+		f, again := frames.Next()
+		if f.PC != 0 && !isKnownFrame(&f) {
+			stack = append(stack, fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line))
+		}
+		if !again {
+			break
+		}
+	}
+	return
+}
+
+// isKnownFrame tests if f is inside a known package or the standard
+// library. knownLock must be held by the caller.
+func isKnownFrame(f *runtime.Frame) bool {
+	if strings.HasSuffix(f.File, "_test.go") {
+		return false
+	}
+	return matchPackage(knownPackages, getPackageName(f.Function)) || containsPartialsOnly(knownPathes, f.File)
+}
+
+// RegisterWrapperPackage adds the packages which wrap hedzr/log, such
+// as your in-house logging facade, into the known packages. The frames
+// inside a known package are skipped by CalcStackFrames, so that the
+// caller of your facade will be reported.
+//
+// A package path ended with "/..." matches the package and all its
+// sub-packages, for example:
+//
+//	log.RegisterWrapperPackage("github.com/acme/kit/logging/...")
+func RegisterWrapperPackage(pkg ...string) {
+	callerInitOnce.Do(initKnownPackages)
+
+	knownLock.Lock()
+	defer knownLock.Unlock()
+	for _, p := range pkg {
+		if p != "" && !contains(knownPackages, p) {
+			knownPackages = append(knownPackages, p)
+		}
+	}
+}
+
+func initKnownPackages() {
+	knownLock.Lock()
+	defer knownLock.Unlock()
+
+	logPackage := "github.com/hedzr/log" // and logx, logex
+	colorPackage := "github.com/hedzr/log/color"
+	timingPackage := "github.com/hedzr/log/timing"
+	logrusPackage := "github.com/sirupsen/logrus"
+	errorsPackage := "gopkg.in/hedzr/errors.v..."
+	knownPackages = append(knownPackages, "runtime", "reflect", logPackage, colorPackage, timingPackage, logrusPackage, errorsPackage)
+
+	// the source files of the standard library, for the toolchain
+	// which built this binary
+	knownPathes = []string{"/usr/local/go/src/"}
+	if root := runtime.GOROOT(); root != "" {
+		if p := path.Clean(filepath.ToSlash(root)) + "/src/"; p != knownPathes[0] {
+			knownPathes = append(knownPathes, p)
+		}
+	}
+}
+
 var (
 	knownPackages  []string
 	knownPathes    []string
+	knownLock      sync.RWMutex
 	callerInitOnce sync.Once
 )
 
 const (
 	maximumCallerDepth int = 25
+	maximumStackDepth  int = 64
 )
 
 // getPackageName reduces a fully qualified function name to the package name
@@ -80,3 +163,19 @@ func containsPartialsOnly(partialNames []string, testedString string) (contains
 	}
 	return
 }
+
+// matchPackage tests if pkg is one of the patterns. A pattern ended
+// with "..." matches the packages with that prefix.
+func matchPackage(patterns []string, pkg string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "...") {
+			prefix := strings.TrimSuffix(p, "...")
+			if strings.HasPrefix(pkg, prefix) || pkg == strings.TrimSuffix(prefix, "/") {
+				return true
+			}
+		} else if strings.EqualFold(p, pkg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestCalcStackFrames(t *testing.T) {
+	if skipped := CalcStackFrames(0); skipped != 0 {
+		t.Fatalf("expect no frames skipped in a test file, but got %d", skipped)
+	}
+
+	_, f := calcStackFrames(0)
+	if f.Function != "github.com/hedzr/log.TestCalcStackFrames" {
+		t.Fatalf("bad caller: %v", f.Function)
+	}
+}
+
+func TestRegisterWrapperPackage(t *testing.T) {
+	// the registered packages would be skipped by the other tests
+	callerInitOnce.Do(initKnownPackages)
+	knownLock.RLock()
+	saved := append([]string(nil), knownPackages...)
+	knownLock.RUnlock()
+	defer func() {
+		knownLock.Lock()
+		knownPackages = saved
+		knownLock.Unlock()
+	}()
+
+	RegisterWrapperPackage("github.com/acme/kit/logging", "github.com/acme/facade/...")
+	RegisterWrapperPackage("github.com/acme/kit/logging")
+
+	var n int
+	for _, p := range knownPackages {
+		if p == "github.com/acme/kit/logging" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("expect the package registered once, but got %d", n)
+	}
+
+	for pkg, expect := range map[string]bool{
+		"github.com/acme/kit/logging":       true,
+		"github.com/acme/kit/logging/sub":   false,
+		"github.com/acme/facade":            true,
+		"github.com/acme/facade/zap":        true,
+		"github.com/acme/facadex":           false,
+		"gopkg.in/hedzr/errors.v3":          true,
+		"github.com/hedzr/log":              true,
+		"github.com/hedzr/log/color":        true,
+		"github.com/hedzr/log/exec":         false,
+		"github.com/someone/else/logging/x": false,
+	} {
+		if matchPackage(knownPackages, pkg) != expect {
+			t.Fatalf("matching %q, expect %v", pkg, expect)
+		}
+	}
+}
+
+func TestKnownPathes(t *testing.T) {
+	callerInitOnce.Do(initKnownPackages)
+
+	_, file, _, _ := runtime.Caller(0)
+	if containsPartialsOnly(knownPathes, file) {
+		t.Fatalf("%q should not be treated as a GOROOT file", file)
+	}
+
+	pc, _, _, _ := runtime.Caller(1) // testing.tRunner
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if !containsPartialsOnly(knownPathes, f.File) {
+		t.Fatalf("%q should be treated as a GOROOT file, known pathes are %v", f.File, knownPathes)
+	}
+}
@@ -1,6 +1,8 @@
 package states
 
 import (
+	"sync"
+
 	"github.com/hedzr/log/buildtags"
 	"github.com/hedzr/log/isdelve"
 	"github.com/hedzr/log/trace"
@@ -40,6 +42,60 @@ func Env() CmdrMinimal { return env }
 
 var env = &minimalEnv{}
 
+// OnChanged registers fn to be called after the debug, trace, verbose
+// or quiet states have been changed by the setters of Env(), and
+// returns a function to unregister it.
+//
+// fn is called synchronously in the goroutine of the setter.
+func OnChanged(fn func()) (remove func()) {
+	h := &hook{fn}
+	hooksLock.Lock()
+	hooks = append(hooks, h)
+	hooksLock.Unlock()
+	return func() {
+		hooksLock.Lock()
+		defer hooksLock.Unlock()
+		for i, x := range hooks {
+			if x == h {
+				hooks = append(hooks[:i:i], hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+type hook struct{ fn func() }
+
+var (
+	hooksLock sync.RWMutex
+	hooks     []*hook
+)
+
+// setBool sets *p and calls the hooks if it's changed
+func setBool(p *bool, b bool) {
+	if *p != b {
+		*p = b
+		changed()
+	}
+}
+
+// setInt sets *p and calls the hooks if it's changed
+func setInt(p *int, v int) {
+	if *p != v {
+		*p = v
+		changed()
+	}
+}
+
+func changed() {
+	hooksLock.RLock()
+	hs := hooks
+	hooksLock.RUnlock()
+	for _, h := range hs {
+		h.fn()
+	}
+}
+
 // minimalEnv structure holds the debug/trace flags and provides CmdrMinimal accessors
 type minimalEnv struct {
 	debugMode    bool
@@ -61,17 +117,17 @@ func (e *minimalEnv) InDebugging() bool { return isdelve.Enabled }
 func (e *minimalEnv) GetDebugMode() bool { return e.debugMode || isdelve.Enabled }
 
 // SetDebugMode set the debug boolean flag generally.
-func (e *minimalEnv) SetDebugMode(b bool)    { e.debugMode = b }
+func (e *minimalEnv) SetDebugMode(b bool)    { setBool(&e.debugMode, b) }
 func (e *minimalEnv) GetDebugLevel() int     { return e.debugLevel }
-func (e *minimalEnv) SetDebugLevel(hits int) { e.debugLevel = hits }
+func (e *minimalEnv) SetDebugLevel(hits int) { setInt(&e.debugLevel, hits) }
 
 // GetTraceMode return the trace boolean flag generally.
 func (e *minimalEnv) GetTraceMode() bool { return e.traceMode || trace.IsEnabled() }
 
 // SetTraceMode set the trace boolean flag generally.
-func (e *minimalEnv) SetTraceMode(b bool)    { e.traceMode = b }
+func (e *minimalEnv) SetTraceMode(b bool)    { setBool(&e.traceMode, b) }
 func (e *minimalEnv) GetTraceLevel() int     { return e.traceLevel }
-func (e *minimalEnv) SetTraceLevel(hits int) { e.traceLevel = hits }
+func (e *minimalEnv) SetTraceLevel(hits int) { setInt(&e.traceLevel, hits) }
 
 func (e *minimalEnv) IsNoColorMode() bool      { return e.noColorMode }
 func (e *minimalEnv) SetNoColorMode(b bool)    { e.noColorMode = b }
@@ -80,11 +136,11 @@ func (e *minimalEnv) SetNoColorCount(hits int) { e.noColorCount = hits }
 
 func (e *minimalEnv) IsVerboseMode() bool      { return buildtags.VerboseEnabled || e.verboseMode }
 func (e *minimalEnv) IsVerboseModePure() bool  { return e.verboseMode }
-func (e *minimalEnv) SetVerboseMode(b bool)    { e.verboseMode = b }
+func (e *minimalEnv) SetVerboseMode(b bool)    { setBool(&e.verboseMode, b) }
 func (e *minimalEnv) CountOfVerbose() int      { return e.verboseCount }
-func (e *minimalEnv) SetVerboseCount(hits int) { e.verboseCount = hits }
+func (e *minimalEnv) SetVerboseCount(hits int) { setInt(&e.verboseCount, hits) }
 
 func (e *minimalEnv) IsQuietMode() bool      { return e.quietMode }
-func (e *minimalEnv) SetQuietMode(b bool)    { e.quietMode = b }
+func (e *minimalEnv) SetQuietMode(b bool)    { setBool(&e.quietMode, b) }
 func (e *minimalEnv) CountOfQuiet() int      { return e.quietCount }
-func (e *minimalEnv) SetQuietCount(hits int) { e.quietCount = hits }
+func (e *minimalEnv) SetQuietCount(hits int) { setInt(&e.quietCount, hits) }
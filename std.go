@@ -4,29 +4,96 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedzr/log/buildtags"
 )
 
+// NewStdLogger return a stdlib `log` logger
+func NewStdLogger() Logger {
+	return newStdLogger()
+}
+
+// NewStdLoggerWithConfig return a stdlib `log` logger
+func NewStdLoggerWithConfig(config *LoggerConfig) Logger {
+	return newStdLoggerWithConfig(config)
+}
+
 // newStdLogger return a stdlib `log` logger
 func newStdLogger() Logger {
-	return &stdLogger{Level: InfoLevel, skip: 1, fields: make(map[string]interface{})}
+	return &stdLogger{level: int32(InfoLevel), skip: 1, fields: make(map[string]interface{})}
 }
 
 // newStdLoggerWith return a stdlib `log` logger
 func newStdLoggerWith(lvl Level) Logger {
-	return &stdLogger{Level: lvl, skip: 1, fields: make(map[string]interface{})}
+	return &stdLogger{level: int32(lvl), skip: 1, fields: make(map[string]interface{})}
 }
 
 // newStdLoggerWithConfig return a stdlib `log` logger
-func newStdLoggerWithConfig(config *LoggerConfig) Logger { //nolint:deadcode,unused //future code
+func newStdLoggerWithConfig(config *LoggerConfig) Logger {
 	l, _ := ParseLevel(config.Level)
-	return &stdLogger{Level: l, skip: 1, fields: make(map[string]interface{})}
+	s := &stdLogger{level: int32(l), skip: 1, fields: make(map[string]interface{})}
+	s.conf.Store(newStdConf(config))
+	return s
 }
 
 type stdLogger struct {
-	Level
+	level  int32        // the Level, accessed atomically
+	conf   atomic.Value // stdConf, replaced by ApplyConfig
 	skip   int
 	fields map[string]interface{}
+	err    error
+}
+
+// stdConf holds the settings of LoggerConfig used by a std logger
+type stdConf struct {
+	format    string // text, json
+	caller    string // short, long, func, package, none
+	multiline string // raw, indent, escape, split
+	stackAt   Level  // capture the stack trace at or above this level
+	stackOn   bool
+}
+
+func newStdConf(config *LoggerConfig) stdConf {
+	c := stdConf{format: strings.ToLower(config.Format), caller: strings.ToLower(config.CallerFormat),
+		multiline: strings.ToLower(config.Multiline)}
+	if config.StacktraceLevel != "" {
+		if sl, err := ParseLevel(config.StacktraceLevel); err == nil && sl != OffLevel {
+			c.stackAt, c.stackOn = sl, true
+		}
+	}
+	return c
+}
+
+func (s *stdLogger) getConf() stdConf {
+	c, _ := s.conf.Load().(stdConf)
+	return c
+}
+
+// stdConfigKeys are the json/yaml keys of LoggerConfig applied by a std
+// logger, the others such as target and maxsize are ignored by it
+var stdConfigKeys = []string{"level", "debug", "trace", "format", "caller", "stacktrace", "multiline"}
+
+// ApplyConfig implements CL interface. The target, directory and
+// rotation settings are not supported, since a std logger writes
+// through stdlib log only.
+func (s *stdLogger) ApplyConfig(config *LoggerConfig, keys []string) error {
+	var unsupported []string
+	for _, k := range keys {
+		if !contains(stdConfigKeys, k) {
+			unsupported = append(unsupported, k)
+		}
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%s not supported by the std logger", strings.Join(unsupported, ", "))
+	}
+	s.conf.Store(newStdConf(config))
+	if l, err := ParseLevel(config.Level); err == nil && (contains(keys, "level") || contains(keys, "debug") || contains(keys, "trace")) {
+		s.SetLevel(l)
+	}
+	return nil
 }
 
 // extraSkipFramesFromLogPackage used for hedzr/log package functions:
@@ -35,124 +102,209 @@ type stdLogger struct {
 const extraSkipFramesFromLogPackage = 1
 const skipFrames = 2 + extraSkipFramesFromLogPackage
 
-func (s *stdLogger) AddSkip(skip int) Logger { return &stdLogger{Level: s.Level, skip: s.skip + skip} }
+// clone returns a child logger which shares nothing mutable with s
+func (s *stdLogger) clone() *stdLogger {
+	c := &stdLogger{level: int32(s.GetLevel()), skip: s.skip, err: s.err, fields: make(map[string]interface{}, len(s.fields))}
+	c.conf.Store(s.getConf())
+	for k, v := range s.fields {
+		c.fields[k] = v
+	}
+	return c
+}
+
+// AddSkip returns a child logger with extra frames to skip.
+//
+// Since the caller is located automatically, the skip count is used
+// by stdlib log.Output only. Use RegisterWrapperPackage if your own
+// logging facade needs to be skipped.
+func (s *stdLogger) AddSkip(skip int) Logger {
+	c := s.clone()
+	c.skip += skip
+	return c
+}
+
+func (s *stdLogger) out(lvl Level, args ...interface{}) {
+	_ = s.emit(lvl, fmt.Sprint(args...))
+}
+
+func (s *stdLogger) outln(lvl Level, args ...interface{}) {
+	_ = s.emit(lvl, fmt.Sprintln(args...))
+}
 
-func (s *stdLogger) out(args ...interface{}) {
-	str := fmt.Sprint(args...)
-	_ = log.Output(skipFrames+s.skip, str)
+func (s *stdLogger) outf(lvl Level, msg string, args ...interface{}) {
+	_ = s.emit(lvl, fmt.Sprintf(msg, args...))
+}
+
+// emit builds an entry and sends it to the formatter selected by
+// LoggerConfig.Format. The entry is masked by the Redactor (see
+// SetRedactor) before either formatter writes it.
+//
+// The caller is located by CalcStackFrames rather than a fixed count
+// of frames, so it keeps right while the logger is wrapped by the
+// color package or an adapter.
+func (s *stdLogger) emit(lvl Level, msg string) error {
+	if buildtags.VeryQuietEnabled {
+		return nil // the Fatal and Panic paths still exit and panic
+	}
+	conf := s.getConf()
+	ent := &entry{Time: time.Now(), Level: lvl, Message: msg, Fields: s.fields}
+	if conf.caller != CallerNone {
+		ent.Caller = callerFrame()
+	}
+	if s.err != nil {
+		ent.Err = renderError(s.err)
+		ent.Stack = ent.Err.Stack
+	}
+	if ent.Stack == nil && conf.stackOn && conf.stackAt.Allows(lvl) {
+		ent.Stack = captureStack(0)
+	}
+	GetRedactor().redactEntry(ent)
+	if conf.format == "json" {
+		return writeJSON(s.GetOutput(), ent, conf.caller)
+	}
+	text := formatText(ent, conf.caller, conf.multiline)
+	if conf.multiline == MultilineSplit {
+		for _, line := range splitText(ent, conf.caller, text) {
+			if err := log.Output(skipFrames+s.skip+1, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return log.Output(skipFrames+s.skip+1, text)
 }
 
 func (s *stdLogger) With(key string, val interface{}) Logger {
-	s.fields[key] = val
-	return s
+	c := s.clone()
+	c.fields[key] = val
+	return c
 }
 
 func (s *stdLogger) WithFields(fields map[string]interface{}) Logger {
+	c := s.clone()
 	for key, val := range fields {
-		s.fields[key] = val
+		c.fields[key] = val
 	}
-	return s
+	return c
+}
+
+func (s *stdLogger) WithError(err error) Logger {
+	c := s.clone()
+	c.err = err
+	return c
 }
 
 func (s *stdLogger) Trace(args ...interface{}) {
-	if s.Level >= TraceLevel {
-		s.out(args...)
+	if s.GetLevel().Allows(TraceLevel) {
+		s.out(TraceLevel, args...)
 	}
 }
 
 func (s *stdLogger) Debug(args ...interface{}) {
-	if s.Level >= DebugLevel {
-		s.out(args...)
+	if s.GetLevel().Allows(DebugLevel) {
+		s.out(DebugLevel, args...)
 	}
 }
 
 func (s *stdLogger) Info(args ...interface{}) {
-	if s.Level >= InfoLevel {
-		s.out(args...)
+	if s.GetLevel().Allows(InfoLevel) {
+		s.out(InfoLevel, args...)
 	}
 }
 
 func (s *stdLogger) Warn(args ...interface{}) {
-	s.out(args...)
+	if s.GetLevel().Allows(WarnLevel) {
+		s.out(WarnLevel, args...)
+	}
 }
 
 func (s *stdLogger) Error(args ...interface{}) {
-	s.out(args...)
+	if s.GetLevel().Allows(ErrorLevel) {
+		s.out(ErrorLevel, args...)
+	}
 }
 
 func (s *stdLogger) Fatal(args ...interface{}) {
-	s.out(args...)
-	if InTesting() {
-		panic(fmt.Sprint(args...))
-	}
-	os.Exit(1)
+	s.out(FatalLevel, args...)
+	exitOnFatal(fmt.Sprint(args...))
 }
 
 func (s *stdLogger) Panic(args ...interface{}) {
-	str := fmt.Sprint(args...)
-	_ = log.Output(skipFrames+s.skip, str)
-	panic(str)
+	s.out(PanicLevel, args...)
+	panic(fmt.Sprint(args...))
 }
 
 func (s *stdLogger) Print(args ...interface{}) {
-	s.out(args...)
+	s.out(InfoLevel, args...)
 }
 
 func (s *stdLogger) Println(args ...interface{}) {
-	str := fmt.Sprintln(args...)
-	_ = log.Output(skipFrames+s.skip, str)
-}
-
-func (s *stdLogger) outf(msg string, args ...interface{}) {
-	str := fmt.Sprintf(msg, args...)
-	_ = log.Output(skipFrames+s.skip, str)
+	s.outln(InfoLevel, args...)
 }
 
 func (s *stdLogger) Tracef(msg string, args ...interface{}) {
-	if s.Level >= TraceLevel {
-		s.outf(msg, args...)
+	if s.GetLevel().Allows(TraceLevel) {
+		s.outf(TraceLevel, msg, args...)
 	}
 }
 
 func (s *stdLogger) Debugf(msg string, args ...interface{}) {
-	if s.Level >= DebugLevel {
-		s.outf(msg, args...)
+	if s.GetLevel().Allows(DebugLevel) {
+		s.outf(DebugLevel, msg, args...)
 	}
 }
 
 func (s *stdLogger) Infof(msg string, args ...interface{}) {
-	if s.Level >= InfoLevel {
-		s.outf(msg, args...)
+	if s.GetLevel().Allows(InfoLevel) {
+		s.outf(InfoLevel, msg, args...)
 	}
 }
 
 func (s *stdLogger) Warnf(msg string, args ...interface{}) {
-	s.outf(msg, args...)
+	if s.GetLevel().Allows(WarnLevel) {
+		s.outf(WarnLevel, msg, args...)
+	}
 }
 
 func (s *stdLogger) Errorf(msg string, args ...interface{}) {
-	s.outf(msg, args...)
+	if s.GetLevel().Allows(ErrorLevel) {
+		s.outf(ErrorLevel, msg, args...)
+	}
 }
 
 func (s *stdLogger) Fatalf(msg string, args ...interface{}) {
-	s.outf(msg, args...)
-	if InTesting() {
-		panic(fmt.Sprintf(msg, args...))
-	}
-	os.Exit(1)
+	s.outf(FatalLevel, msg, args...)
+	exitOnFatal(fmt.Sprintf(msg, args...))
 }
 
 func (s *stdLogger) Panicf(msg string, args ...interface{}) {
-	str := fmt.Sprintf(msg, args...)
-	_ = log.Output(skipFrames+s.skip, str)
-	panic(str)
+	s.outf(PanicLevel, msg, args...)
+	panic(fmt.Sprintf(msg, args...))
 }
 
 func (s *stdLogger) Printf(msg string, args ...interface{}) {
-	s.outf(msg, args...)
+	s.outf(InfoLevel, msg, args...)
+}
+
+// Logf implements LL interface. Like Fatalf and Panicf, the levels at
+// or above FatalLevel in severity are not gated. It never exits or
+// panics, even for FatalLevel and PanicLevel.
+func (s *stdLogger) Logf(lvl Level, msg string, args ...interface{}) {
+	if sev := lvl.Severity(); sev >= 0 && (sev <= FatalLevel.Severity() || s.GetLevel().Allows(lvl)) {
+		s.outf(lvl, msg, args...)
+	}
+}
+
+// Output implements OL interface, it is Logf with the error of the
+// output device returned.
+func (s *stdLogger) Output(lvl Level, msg string) error {
+	if sev := lvl.Severity(); sev >= 0 && (sev <= FatalLevel.Severity() || s.GetLevel().Allows(lvl)) {
+		return s.emit(lvl, msg)
+	}
+	return nil
 }
 
-func (s *stdLogger) SetLevel(lvl Level)      { s.Level = lvl }
-func (s *stdLogger) GetLevel() Level         { return s.Level }
+func (s *stdLogger) SetLevel(lvl Level)      { atomic.StoreInt32(&s.level, int32(lvl)) }
+func (s *stdLogger) GetLevel() Level         { return Level(atomic.LoadInt32(&s.level)) }
 func (s *stdLogger) SetOutput(out io.Writer) {}
 func (s *stdLogger) Setup()                  {}
@@ -0,0 +1,270 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedzr/log/exec"
+)
+
+// entry holds everything the built-in formatters need to render one
+// logging line.
+type entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  map[string]interface{}
+	Err     *errorInfo
+	Stack   []string // from Err or captured for LoggerConfig.StacktraceLevel
+	Caller  *runtime.Frame
+}
+
+// The available formats of LoggerConfig.CallerFormat
+const (
+	CallerShort   = "short"   // file.go:12
+	CallerLong    = "long"    // /path/to/file.go:12
+	CallerFunc    = "func"    // pkg.Function:12
+	CallerPackage = "package" // github.com/x/pkg/file.go:12
+	CallerNone    = "none"    // no caller reported
+)
+
+// The available modes of LoggerConfig.Multiline, which tell the text
+// formatter how to print a message with several lines
+const (
+	MultilineRaw    = "raw"    // print the lines as is
+	MultilineIndent = "indent" // indent the continuation lines under the message
+	MultilineEscape = "escape" // escape the newlines as \n
+	MultilineSplit  = "split"  // print one entry per line, see MultilineField
+)
+
+// MultilineField is the correlation field shared by the entries split
+// from one message in MultilineSplit mode
+const MultilineField = "multiline_id"
+
+// callerFrame returns the first frame outside hedzr/log and the other
+// known logging packages, see CalcStackFrames.
+func callerFrame() *runtime.Frame {
+	_, f := calcStackFrames(1)
+	if f.PC == 0 {
+		return nil
+	}
+	return &f
+}
+
+// callerFile returns the file part of a caller in the given format
+func callerFile(f *runtime.Frame, format string) string {
+	switch format {
+	case CallerLong:
+		return f.File
+	case CallerFunc:
+		fn := f.Function
+		if i := strings.LastIndex(fn, "/"); i >= 0 {
+			fn = fn[i+1:]
+		}
+		return fn
+	case CallerPackage:
+		return getPackageName(f.Function) + "/" + path.Base(f.File)
+	}
+	return path.Base(f.File)
+}
+
+// outputLock serializes the writes of formatted entries, stdlib
+// log.Output has its own lock so the text formatter needn't it.
+var outputLock sync.Mutex
+
+// formatText renders ent as a plain text line for stdlib log.Output.
+//
+// The fields are appended in key=value form and sorted by key. An
+// attached error is appended as error=..., and its causes and the
+// stack trace follow in the next lines.
+//
+// A message with several lines is printed as is in MultilineRaw mode.
+// In the other modes, the fields follow the first line of message and
+// the continuation lines are indented, and then the whole text is
+// escaped into one line in MultilineEscape mode.
+func formatText(ent *entry, callerFormat, multiline string) string {
+	var sb strings.Builder
+	sb.WriteString(callerPrefix(ent, callerFormat))
+	msg, rest := strings.TrimRight(ent.Message, "\n"), ""
+	if multiline != "" && multiline != MultilineRaw {
+		if i := strings.IndexByte(msg, '\n'); i >= 0 {
+			msg, rest = msg[:i], msg[i+1:]
+		}
+	}
+	sb.WriteString(msg)
+	for _, k := range sortedKeys(ent.Fields) {
+		sb.WriteByte(' ')
+		sb.WriteString(fieldKey(k, textKeys))
+		sb.WriteByte('=')
+		sb.WriteString(textValue(ent.Fields[k]))
+	}
+	if ent.Err != nil {
+		sb.WriteString(" error=")
+		sb.WriteString(textValue(ent.Err.Message))
+	}
+	if rest != "" {
+		sb.WriteByte('\n')
+		sb.WriteString(strings.TrimRight(exec.LeftPad(rest, 4), "\n"))
+	}
+	if ent.Err != nil {
+		ent.Err.writeCauses(&sb, "    ")
+	}
+	if len(ent.Stack) > 0 {
+		sb.WriteString("\n    stack:")
+		for _, f := range ent.Stack {
+			sb.WriteString("\n      ")
+			sb.WriteString(f)
+		}
+	}
+	if multiline == MultilineEscape {
+		return strings.Replace(sb.String(), "\n", `\n`, -1)
+	}
+	return sb.String()
+}
+
+// splitText splits the text from formatText into the lines for
+// MultilineSplit mode. Each line is prefixed with the caller and tagged
+// with a shared MultilineField.
+func splitText(ent *entry, callerFormat, text string) []string {
+	if strings.IndexByte(text, '\n') < 0 {
+		return []string{text}
+	}
+	prefix := callerPrefix(ent, callerFormat)
+	tag := " " + MultilineField + "=" + strconv.FormatUint(atomic.AddUint64(&multilineSeq, 1), 36)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			line = prefix + line
+		}
+		lines[i] = line + tag
+	}
+	return lines
+}
+
+// multilineSeq generates the values of MultilineField
+var multilineSeq uint64
+
+func callerPrefix(ent *entry, callerFormat string) string {
+	if ent.Caller == nil {
+		return ""
+	}
+	return callerFile(ent.Caller, callerFormat) + ":" + strconv.Itoa(ent.Caller.Line) + ": "
+}
+
+// writeJSON renders ent as a JSON object in one line and writes it to w.
+//
+// The keys time, level, caller and msg come first, the fields follow
+// in key order, and an attached error and the stack trace are put into
+// the structured error and stack fields. A field named as one of these
+// keys is prefixed with "fields.", see fieldKey.
+func writeJSON(w io.Writer, ent *entry, callerFormat string) (err error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONPair(&buf, "time", ent.Time.Format(time.RFC3339Nano), true)
+	writeJSONPair(&buf, "level", ent.Level.String(), false)
+	if ent.Caller != nil {
+		writeJSONPair(&buf, "caller", &jsonCaller{
+			File:     callerFile(ent.Caller, callerFormat),
+			Line:     ent.Caller.Line,
+			Function: ent.Caller.Function,
+		}, false)
+	}
+	writeJSONPair(&buf, "msg", strings.TrimRight(ent.Message, "\n"), false)
+	for _, k := range sortedKeys(ent.Fields) {
+		writeJSONPair(&buf, fieldKey(k, jsonKeys), jsonValue(ent.Fields[k]), false)
+	}
+	if ent.Err != nil {
+		writeJSONPair(&buf, "error", ent.Err, false)
+	}
+	if len(ent.Stack) > 0 {
+		writeJSONPair(&buf, "stack", ent.Stack, false)
+	}
+	buf.WriteString("}\n")
+
+	outputLock.Lock()
+	defer outputLock.Unlock()
+	_, err = w.Write(buf.Bytes())
+	return
+}
+
+type jsonCaller struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+}
+
+func writeJSONPair(buf *bytes.Buffer, key string, val interface{}, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	b, _ := json.Marshal(key)
+	buf.Write(b)
+	buf.WriteByte(':')
+	b, err := json.Marshal(val)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%+v", val))
+	}
+	buf.Write(b)
+}
+
+// jsonValue makes sure an error value is rendered as its message
+// instead of an empty object.
+func jsonValue(v interface{}) interface{} {
+	if e, ok := v.(error); ok && e != nil {
+		return e.Error()
+	}
+	return v
+}
+
+func textValue(v interface{}) string {
+	var s string
+	switch x := v.(type) {
+	case string:
+		s = x
+	case error:
+		s = x.Error()
+	default:
+		s = fmt.Sprintf("%+v", v)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// The keys written by the formatters themselves, a field with one of
+// these names is renamed by fieldKey
+var (
+	textKeys = []string{"error", MultilineField}
+	jsonKeys = []string{"time", "level", "caller", "msg", "error", "stack"}
+)
+
+// fieldKey returns key prefixed with "fields." if it is one of the
+// reserved keys, so that a field never collides with the keys written
+// by a formatter, such as With("error", ...) and WithError
+func fieldKey(key string, reserved []string) string {
+	for _, r := range reserved {
+		if key == r {
+			return "fields." + key
+		}
+	}
+	return key
+}
+
+func sortedKeys(m map[string]interface{}) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
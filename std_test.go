@@ -1,6 +1,9 @@
 package log
 
 import (
+	"encoding/json"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -13,17 +16,17 @@ func TestStdLogger_atTopLevel(t *testing.T) {
 	Tracef("hello trace")
 }
 
-// TestNormal used logger directly so the caller is wrong.
-// To get the right filename and line number of caller, see also TestTopLevel
+// TestStdLogger_Normal used logger directly, the caller is still right
+// since it is located by CalcStackFrames.
 func TestStdLogger_Normal(t *testing.T) {
 	// config := log.NewLoggerConfigWith(true, "logrus", "trace")
 	// logger := logrus.NewWithConfig(config)
-	logger.Printf("hello")
-	logger.Infof("hello info")
-	logger.Warnf("hello warn")
-	logger.Errorf("hello error")
-	logger.Debugf("hello debug")
-	logger.Tracef("hello trace")
+	GetLogger().Printf("hello")
+	GetLogger().Infof("hello info")
+	GetLogger().Warnf("hello warn")
+	GetLogger().Errorf("hello error")
+	GetLogger().Debugf("hello debug")
+	GetLogger().Tracef("hello trace")
 }
 
 func TestStdLogger(t *testing.T) {
@@ -43,6 +46,37 @@ func TestStdLogger(t *testing.T) {
 	l.Error("error")
 }
 
+func TestStdLogger_gating(t *testing.T) {
+	requireOutput(t)
+	for lvl, want := range map[Level]string{
+		NoticeLevel:   "warn|error|warnf|errorf",
+		ErrorLevel:    "error|errorf",
+		CriticalLevel: "",
+		InfoLevel:     "info|warn|error|infof|warnf|errorf",
+	} {
+		l := newStdLoggerWith(lvl)
+		out := captureStdOutput(func() {
+			AsL(l).Trace("trace")
+			AsL(l).Debug("debug")
+			AsL(l).Info("info")
+			AsL(l).Warn("warn")
+			AsL(l).Error("error")
+			l.Tracef("tracef")
+			l.Debugf("debugf")
+			l.Infof("infof")
+			l.Warnf("warnf")
+			l.Errorf("errorf")
+		})
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			got = append(got, line[strings.LastIndexByte(line, ' ')+1:])
+		}
+		if strings.Join(got, "|") != want {
+			t.Fatalf("at %v: want %q, got %q", lvl, want, out)
+		}
+	}
+}
+
 func TestStdLogger_More(t *testing.T) {
 	log := newStdLoggerWith(TraceLevel)
 	log.Printf("")
@@ -52,3 +86,129 @@ func TestStdLogger_More(t *testing.T) {
 	tf(log)
 	tp(log)
 }
+
+func TestStdLogger_CallerFormat(t *testing.T) {
+	requireOutput(t)
+	for cf, expect := range map[string]string{
+		CallerShort:   " std_test.go:",
+		CallerLong:    "/std_test.go:",
+		CallerFunc:    " log.TestStdLogger_CallerFormat.func1:",
+		CallerPackage: " github.com/hedzr/log/std_test.go:",
+	} {
+		l := NewStdLoggerWithConfig(NewLoggerConfig(WithCaller(cf)))
+		out := captureStdOutput(func() { l.Infof("hello") })
+		if !strings.Contains(out, expect) {
+			t.Fatalf("caller format %q: expect %q in %q", cf, expect, out)
+		}
+	}
+
+	l := NewStdLoggerWithConfig(NewLoggerConfig(WithCaller(CallerNone)))
+	if out := captureStdOutput(func() { l.Infof("hello") }); strings.Contains(out, "std_test.go") {
+		t.Fatalf("expect no caller in %q", out)
+	}
+}
+
+func TestStdLogger_CallerJSON(t *testing.T) {
+	requireOutput(t)
+	l := NewStdLoggerWithConfig(NewLoggerConfig(func(lc *LoggerConfig) { lc.Format = "json" }))
+	out := captureStdOutput(func() { AsL(l).Info("hello") })
+
+	var m struct {
+		Caller jsonCaller `json:"caller"`
+	}
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Caller.File != "std_test.go" || m.Caller.Line == 0 || !strings.HasSuffix(m.Caller.Function, "TestStdLogger_CallerJSON.func2") {
+		t.Fatalf("bad caller: %+v", m.Caller)
+	}
+}
+
+func TestStdLogger_fieldCollision(t *testing.T) {
+	requireOutput(t)
+	l := NewStdLoggerWithConfig(NewLoggerConfig(WithCaller(CallerNone), func(lc *LoggerConfig) { lc.Format = "json" }))
+	out := captureStdOutput(func() { l.With("error", "dup").With("msg", "m").(EL).WithError(io.EOF).Warnf("x") })
+	t.Log(out)
+
+	if strings.Count(out, `"error":`) != 1 || strings.Count(out, `"msg":`) != 1 ||
+		!strings.Contains(out, `"fields.error":"dup"`) || !strings.Contains(out, `"fields.msg":"m"`) {
+		t.Fatalf("bad JSON keys: %q", out)
+	}
+
+	l = NewStdLoggerWithConfig(NewLoggerConfig(WithCaller(CallerNone)))
+	out = captureStdOutput(func() { l.With("error", "dup").(EL).WithError(io.EOF).Warnf("x") })
+	if strings.Count(out, " error=") != 1 || !strings.Contains(out, " fields.error=dup") {
+		t.Fatalf("bad text keys: %q", out)
+	}
+}
+
+func TestStdLogger_StacktraceLevel(t *testing.T) {
+	requireOutput(t)
+	l := NewStdLoggerWithConfig(NewLoggerConfig(WithStacktraceLevel("error")))
+	out := captureStdOutput(func() {
+		l.Warnf("no stack")
+		l.Errorf("with stack")
+	})
+	t.Log(out)
+
+	lines := strings.Split(out, "\n")
+	if strings.Contains(lines[1], "stack:") || !strings.Contains(out, "    stack:\n      github.com/hedzr/log.TestStdLogger_StacktraceLevel") {
+		t.Fatalf("bad stack block: %q", out)
+	}
+	if strings.Contains(out, "testing.tRunner") || strings.Contains(out, "log.(*stdLogger)") {
+		t.Fatalf("stack should be filtered: %q", out)
+	}
+
+	l = NewStdLoggerWithConfig(NewLoggerConfig(WithStacktraceLevel("warn"), func(lc *LoggerConfig) { lc.Format = "json" }))
+	out = captureStdOutput(func() { l.Warnf("json stack") })
+
+	var m struct {
+		Stack []string `json:"stack"`
+	}
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Stack) == 0 || !strings.HasPrefix(m.Stack[0], "github.com/hedzr/log.TestStdLogger_StacktraceLevel") {
+		t.Fatalf("bad stack field: %v", m.Stack)
+	}
+}
+
+func TestStdLogger_Multiline(t *testing.T) {
+	requireOutput(t)
+	msg := "command failed:\nline 1\nline 2\n"
+	newL := func(mode string) Logger {
+		return NewStdLoggerWithConfig(NewLoggerConfig(WithMultiline(mode), WithCaller(CallerNone))).With("k", "v")
+	}
+
+	out := captureStdOutput(func() { newL(MultilineRaw).Infof(msg) })
+	if !strings.HasSuffix(out, "command failed:\nline 1\nline 2 k=v\n") {
+		t.Fatalf("bad raw output: %q", out)
+	}
+
+	out = captureStdOutput(func() { newL(MultilineIndent).Infof(msg) })
+	if !strings.HasSuffix(out, "command failed: k=v\n    line 1\n    line 2\n") {
+		t.Fatalf("bad indented output: %q", out)
+	}
+
+	out = captureStdOutput(func() { newL(MultilineEscape).Infof(msg) })
+	if strings.Count(out, "\n") != 1 || !strings.HasSuffix(out, `command failed: k=v\n    line 1\n    line 2`+"\n") {
+		t.Fatalf("bad escaped output: %q", out)
+	}
+
+	out = captureStdOutput(func() { newL(MultilineSplit).Infof(msg) })
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expect 3 entries: %q", out)
+	}
+	tag := lines[0][strings.LastIndex(lines[0], " "+MultilineField+"="):]
+	for i, want := range []string{"command failed: k=v", "    line 1", "    line 2"} {
+		if !strings.HasSuffix(lines[i], want+tag) {
+			t.Fatalf("bad split entry %d: %q", i, lines[i])
+		}
+	}
+
+	out = captureStdOutput(func() { newL(MultilineSplit).Infof("one line") })
+	if strings.Contains(out, MultilineField) {
+		t.Fatalf("a single line should not be tagged: %q", out)
+	}
+}
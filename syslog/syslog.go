@@ -0,0 +1,393 @@
+// Copyright © 2023 Hedzr Yeh.
+
+// Package syslog provides a sink which writes to a syslog daemon over
+// a unix socket, UDP or TCP, in RFC 5424 or RFC 3164 format, for the
+// hosts without systemd-journald.
+//
+// A Writer implements log.SystemdLoggerEx and log.SystemdFieldsLogger,
+// so it can be put into hedzr/log by log.FromSystemdLogger, just like
+// a journald.Journal:
+//
+//	w, err := syslog.New("tcp", "logs.example.com:6514", syslog.WithFacility(syslog.Local0), syslog.WithAppName("app"))
+//	if err == nil {
+//	    log.SetLogger(log.FromSystemdLogger(w))
+//	}
+//
+// The levels of hedzr/log are mapped to the syslog severities by
+// log.Level.SyslogPriority, such as 7 (debug) for DebugLevel.
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedzr/log"
+	"github.com/hedzr/log/buildtags"
+)
+
+// Format is the message format of syslog protocol
+type Format int
+
+const (
+	// RFC5424 is the format of RFC 5424, the fields of an entry are
+	// written as the STRUCTURED-DATA
+	RFC5424 Format = iota
+	// RFC3164 is the legacy BSD format, the fields of an entry are
+	// appended to the message as "KEY=value"
+	RFC3164
+)
+
+// Facility is the syslog facility
+type Facility int
+
+// The facilities defined by RFC 5424
+const (
+	Kern Facility = iota
+	User
+	Mail
+	Daemon
+	Auth
+	Syslog
+	Lpr
+	News
+	Uucp
+	Cron
+	Authpriv
+	Ftp
+	Local0 Facility = iota + 4
+	Local1
+	Local2
+	Local3
+	Local4
+	Local5
+	Local6
+	Local7
+)
+
+// StructuredDataID is the SD-ID of the fields in RFC 5424 format,
+// 32473 is the private enterprise number reserved for documentation
+const StructuredDataID = "fields@32473"
+
+// New returns a Writer connected to the syslog daemon at addr.
+//
+// network is "unix", "unixgram", "udp" or "tcp". If network is empty,
+// the local daemon is connected through /dev/log, /var/run/syslog or
+// /var/run/log. The format is RFC3164 by default then, and the hostname
+// is omitted, as the local daemons expect.
+//
+// Over TCP, the messages are framed by octet counting (RFC 6587).
+func New(network, addr string, opts ...Opt) (*Writer, error) {
+	w := &Writer{
+		network:    network,
+		addr:       addr,
+		facility:   User,
+		appName:    filepath.Base(os.Args[0]),
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+		now:        time.Now,
+	}
+	w.hostname, _ = os.Hostname()
+	for _, opt := range opts {
+		opt(w)
+	}
+	if network == "" && !w.formatSet {
+		w.format = RFC3164
+	}
+	w.dialer = w.dial
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Opt is a functional option for New
+type Opt func(w *Writer)
+
+// WithFormat sets the message format, it's RFC5424 by default, or
+// RFC3164 for the local daemon
+func WithFormat(f Format) Opt {
+	return func(w *Writer) {
+		w.format, w.formatSet = f, true
+	}
+}
+
+// WithFacility sets the facility, it's User by default
+func WithFacility(f Facility) Opt {
+	return func(w *Writer) {
+		w.facility = f
+	}
+}
+
+// WithAppName sets the APP-NAME (or TAG in RFC 3164 format), it's the
+// base name of the executable by default
+func WithAppName(name string) Opt {
+	return func(w *Writer) {
+		w.appName = name
+	}
+}
+
+// WithHostname overrides the hostname reported by os.Hostname
+func WithHostname(name string) Opt {
+	return func(w *Writer) {
+		w.hostname = name
+	}
+}
+
+// WithBackoff sets the delays between the reconnecting attempts. The
+// delay starts from min and doubles after each failure, up to max. It
+// is 100ms to 30s by default. The messages are dropped with an error
+// while waiting.
+func WithBackoff(min, max time.Duration) Opt {
+	return func(w *Writer) {
+		if min > 0 && max >= min {
+			w.minBackoff, w.maxBackoff = min, max
+		}
+	}
+}
+
+// Writer writes the entries to a syslog daemon
+type Writer struct {
+	network, addr string
+	format        Format
+	formatSet     bool // by WithFormat
+	facility      Facility
+	appName       string
+	hostname      string
+	local         bool
+
+	minBackoff, maxBackoff time.Duration
+
+	mu      sync.Mutex
+	conn    net.Conn
+	connNet string // the network of conn, which frames the messages
+	dialer  func() (net.Conn, error)
+	now     func() time.Time
+	backoff time.Duration
+	retryAt time.Time
+	lastErr error
+}
+
+var (
+	_ log.SystemdLoggerEx     = (*Writer)(nil)
+	_ log.SystemdFieldsLogger = (*Writer)(nil)
+)
+
+// ErrNotConnected is returned while waiting to reconnect, see
+// WithBackoff
+var ErrNotConnected = errors.New("syslog: not connected")
+
+// localPaths are the sockets of the local syslog daemons
+var localPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// dial connects to the daemon, and records the network actually
+// connected in w.connNet
+func (w *Writer) dial() (net.Conn, error) {
+	if w.network != "" {
+		w.connNet = w.network
+		return net.Dial(w.network, w.addr)
+	}
+	w.local = true
+	for _, path := range localPaths {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				w.connNet = network
+				return conn, nil
+			}
+		}
+	}
+	return nil, errors.New("syslog: no local syslog daemon found")
+}
+
+// connect dials if the backoff delay has passed. w.mu must be held or
+// not shared yet.
+func (w *Writer) connect() error {
+	if now := w.now(); now.Before(w.retryAt) {
+		return fmt.Errorf("%v, retry in %v: %v", ErrNotConnected, w.retryAt.Sub(now), w.lastErr)
+	}
+	conn, err := w.dialer()
+	if err != nil {
+		if w.backoff *= 2; w.backoff < w.minBackoff {
+			w.backoff = w.minBackoff
+		} else if w.backoff > w.maxBackoff {
+			w.backoff = w.maxBackoff
+		}
+		w.retryAt, w.lastErr = w.now().Add(w.backoff), err
+		return err
+	}
+	w.conn, w.backoff, w.retryAt, w.lastErr = conn, 0, time.Time{}, nil
+	return nil
+}
+
+// Close closes the connection
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// Write logs p at the info severity, so that a Writer can be an
+// io.Writer
+func (w *Writer) Write(p []byte) (int, error) {
+	if err := w.Send(6, string(p), nil); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Send writes msg at priority, from 0 (emerg) to 7 (debug), with the
+// fields.
+//
+// If the connection is broken, Send reconnects and retries once.
+func (w *Writer) Send(priority int, msg string, fields map[string]string) error {
+	if buildtags.VeryQuietEnabled {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	for i := 0; i < 2; i++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				return err
+			}
+		}
+		b := w.frame(w.format1(priority, strings.TrimRight(msg, "\n"), fields))
+		if _, err = w.conn.Write(b); err == nil {
+			return nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+// Logf writes the text at the severity of lvl
+func (w *Writer) Logf(lvl log.Level, format string, a ...interface{}) error {
+	return w.Send(lvl.SyslogPriority(), fmt.Sprintf(format, a...), nil)
+}
+
+// frame delimits a message for a stream connection
+func (w *Writer) frame(msg string) []byte {
+	switch w.connNet {
+	case "tcp", "tcp4", "tcp6":
+		return []byte(strconv.Itoa(len(msg)) + " " + msg)
+	case "unix":
+		return []byte(msg + "\n")
+	}
+	return []byte(msg)
+}
+
+func (w *Writer) format1(priority int, msg string, fields map[string]string) string {
+	pri := int(w.facility)*8 + priority&7
+	ts := w.now()
+	if w.format == RFC3164 {
+		var sb strings.Builder
+		sb.WriteString("<" + strconv.Itoa(pri) + ">" + ts.Format(time.Stamp) + " ")
+		if !w.local {
+			sb.WriteString(header(w.hostname, 255) + " ")
+		}
+		sb.WriteString(w.appName + "[" + strconv.Itoa(os.Getpid()) + "]: " + msg)
+		for _, k := range sortedKeys(fields) {
+			sb.WriteString(" " + k + "=" + fields[k])
+		}
+		return sb.String()
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d - %s %s", pri, ts.Format("2006-01-02T15:04:05.000000Z07:00"),
+		header(w.hostname, 255), header(w.appName, 48), os.Getpid(), structuredData(fields), msg)
+}
+
+// header returns s as a HEADER field of RFC 5424: printable US-ASCII,
+// at most n bytes, or "-" if empty
+func header(s string, n int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < n; i++ {
+		if c := s[i]; c > 32 && c < 127 {
+			b = append(b, c)
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// structuredData returns the fields as an SD-ELEMENT, or "-"
+func structuredData(fields map[string]string) string {
+	keys := sortedKeys(fields)
+	if len(keys) == 0 {
+		return "-"
+	}
+	var sb strings.Builder
+	sb.WriteString("[" + StructuredDataID)
+	for _, k := range keys {
+		name := strings.Map(func(r rune) rune {
+			if r == '=' || r == ']' || r == '"' {
+				return -1
+			}
+			return r
+		}, header(k, 32))
+		sb.WriteString(" " + name + `="`)
+		sb.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(fields[k]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (w *Writer) Emerg(v ...interface{}) error   { return w.Send(0, fmt.Sprint(v...), nil) }
+func (w *Writer) Alert(v ...interface{}) error   { return w.Send(1, fmt.Sprint(v...), nil) }
+func (w *Writer) Crit(v ...interface{}) error    { return w.Send(2, fmt.Sprint(v...), nil) }
+func (w *Writer) Error(v ...interface{}) error   { return w.Send(3, fmt.Sprint(v...), nil) }
+func (w *Writer) Warning(v ...interface{}) error { return w.Send(4, fmt.Sprint(v...), nil) }
+func (w *Writer) Notice(v ...interface{}) error  { return w.Send(5, fmt.Sprint(v...), nil) }
+func (w *Writer) Info(v ...interface{}) error    { return w.Send(6, fmt.Sprint(v...), nil) }
+func (w *Writer) Debug(v ...interface{}) error   { return w.Send(7, fmt.Sprint(v...), nil) }
+
+func (w *Writer) Emergf(format string, a ...interface{}) error {
+	return w.Send(0, fmt.Sprintf(format, a...), nil)
+}
+func (w *Writer) Alertf(format string, a ...interface{}) error {
+	return w.Send(1, fmt.Sprintf(format, a...), nil)
+}
+func (w *Writer) Critf(format string, a ...interface{}) error {
+	return w.Send(2, fmt.Sprintf(format, a...), nil)
+}
+func (w *Writer) Errorf(format string, a ...interface{}) error {
+	return w.Send(3, fmt.Sprintf(format, a...), nil)
+}
+func (w *Writer) Warningf(format string, a ...interface{}) error {
+	return w.Send(4, fmt.Sprintf(format, a...), nil)
+}
+func (w *Writer) Noticef(format string, a ...interface{}) error {
+	return w.Send(5, fmt.Sprintf(format, a...), nil)
+}
+func (w *Writer) Infof(format string, a ...interface{}) error {
+	return w.Send(6, fmt.Sprintf(format, a...), nil)
+}
+func (w *Writer) Debugf(format string, a ...interface{}) error {
+	return w.Send(7, fmt.Sprintf(format, a...), nil)
+}
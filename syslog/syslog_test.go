@@ -0,0 +1,262 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hedzr/log"
+	"github.com/hedzr/log/buildtags"
+)
+
+// requireOutput skips the tests checking the written entries, since
+// nothing is written in a veryquiet build
+func requireOutput(t *testing.T) {
+	if buildtags.VeryQuietEnabled {
+		t.Skip("nothing is written in a veryquiet build")
+	}
+}
+
+var stamp = time.Date(2023, 5, 4, 3, 2, 1, 123456000, time.UTC)
+
+func fixedClock(w *Writer) {
+	w.now = func() time.Time { return stamp }
+}
+
+func TestWriter_udp5424(t *testing.T) {
+	requireOutput(t)
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := New("udp", pc.LocalAddr().String(), WithFacility(Local0), WithAppName("app"), WithHostname("host"), fixedClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err = w.Send(4, "disk is full\n", map[string]string{"DISK": "/dev/sda", "Q": `a"b]c\`}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<132>1 2023-05-04T03:02:01.123456Z host app " + strconv.Itoa(os.Getpid()) +
+		` - [fields@32473 DISK="/dev/sda" Q="a\"b\]c\\"] disk is full`
+	if got := string(buf[:n]); got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestWriter_tcpOctetCounting(t *testing.T) {
+	requireOutput(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	w, err := New("tcp", ln.Addr().String(), WithHostname("host"), WithAppName("app"), fixedClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l := log.FromSystemdLogger(w)
+	l.SetLevel(log.DebugLevel)
+	l.Debugf("line %d\nnext", 1)
+	_ = w.Info("")
+
+	r := bufio.NewReader(conn)
+	for _, want := range []struct{ pri, msg string }{{"<15>", "line 1\nnext"}, {"<14>", ""}} {
+		size, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(size, " "))
+		if err != nil {
+			t.Fatalf("bad frame length %q", size)
+		}
+		b := make([]byte, n)
+		if _, err = io.ReadFull(r, b); err != nil {
+			t.Fatal(err)
+		}
+		prefix := want.pri + "1 2023-05-04T03:02:01.123456Z host app "
+		if !strings.HasPrefix(string(b), prefix) || !strings.HasSuffix(string(b), " - - "+want.msg) {
+			t.Fatalf("bad message %q, want %q", b, want.msg)
+		}
+	}
+}
+
+func TestWriter_unixgram3164(t *testing.T) {
+	requireOutput(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram is not supported")
+	}
+	dir, err := ioutil.TempDir("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := New("unixgram", path, WithFormat(RFC3164), WithFacility(Daemon), WithAppName("app"), WithHostname("host"), fixedClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err = w.Send(3, "failed", map[string]string{"USER_ID": "7"}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<27>May  4 03:02:01 host app[" + strconv.Itoa(os.Getpid()) + "]: failed USER_ID=7"
+	if got := string(buf[:n]); got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestWriter_localStream(t *testing.T) {
+	requireOutput(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("unix is not supported")
+	}
+	dir, err := ioutil.TempDir("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	saved := localPaths
+	localPaths = []string{filepath.Join(dir, "none"), path}
+	defer func() { localPaths = saved }()
+
+	w, err := New("", "", WithAppName("app"), fixedClock) // RFC3164 by default
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, msg := range []string{"first", "second"} {
+		if err = w.Info(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"first", "second"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w := "<14>May  4 03:02:01 app[" + strconv.Itoa(os.Getpid()) + "]: " + want + "\n"; line != w {
+			t.Fatalf("got %q\nwant %q", line, w)
+		}
+	}
+
+	w5424, err := New("", "", WithFormat(RFC5424))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w5424.Close()
+	if w5424.format != RFC5424 {
+		t.Fatal("the format given by WithFormat should be kept")
+	}
+}
+
+func TestWriter_reconnect(t *testing.T) {
+	requireOutput(t)
+	now := stamp
+	var dials int
+	peers := make(chan net.Conn, 1)
+	w := &Writer{network: "tcp", minBackoff: time.Second, maxBackoff: 3 * time.Second, now: func() time.Time { return now }}
+	w.dialer = func() (net.Conn, error) {
+		if dials++; dials <= 3 {
+			return nil, errors.New("refused")
+		}
+		c, peer := net.Pipe()
+		peers <- peer
+		return c, nil
+	}
+
+	for i, backoff := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		if err := w.Info("lost"); err == nil || err.Error() != "refused" {
+			t.Fatalf("#%d: expect the dial error, got %v", i, err)
+		}
+		if err := w.Info("lost"); err == nil || !strings.HasPrefix(err.Error(), ErrNotConnected.Error()) {
+			t.Fatalf("#%d: expect waiting for the backoff, got %v", i, err)
+		}
+		if dials != i+1 {
+			t.Fatalf("#%d: dialed %d times", i, dials)
+		}
+		now = now.Add(backoff)
+	}
+
+	errs := make(chan error)
+	go func() { errs <- w.Info("back") }()
+	buf := make([]byte, 1024)
+	n, err := (<-peers).Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if b := string(buf[:n]); !strings.HasSuffix(b, " back") {
+		t.Fatalf("bad message %q", b)
+	}
+	if w.backoff != 0 || !w.retryAt.IsZero() {
+		t.Fatalf("the backoff should be reset: %v %v", w.backoff, w.retryAt)
+	}
+}
+
+func TestNew_refused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	if _, err = New("tcp", addr); err == nil {
+		t.Fatal("expect an error")
+	}
+}